@@ -0,0 +1,185 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"unicode"
+
+	"github.com/colinjfw/sqlkit/db"
+)
+
+// config controls the generated code.
+type config struct {
+	pkg       string
+	dialect   db.Dialect
+	tables    []string // Tables to generate, all tables if empty.
+	constants bool     // Generate column name constants.
+	tableName bool     // Generate a TableName method.
+}
+
+// generate returns formatted Go source declaring a struct for each table.
+func generate(schema db.Schema, c config) ([]byte, error) {
+	tables := schema.Tables
+	if len(c.tables) > 0 {
+		tables = nil
+		for _, name := range c.tables {
+			t, ok := schema.Table(name)
+			if !ok {
+				return nil, fmt.Errorf("sqlkit-gen: unknown table %q", name)
+			}
+			tables = append(tables, t)
+		}
+	}
+
+	var body bytes.Buffer
+	var usesTime bool
+	structs := map[string]string{}
+	for _, t := range tables {
+		name := singular(goName(t.Name))
+		if other, ok := structs[name]; ok {
+			return nil, fmt.Errorf("sqlkit-gen: tables %q and %q both generate %s", other, t.Name, name)
+		}
+		structs[name] = t.Name
+
+		fields := make([]string, len(t.Columns))
+		seen := map[string]bool{"TableName": c.tableName}
+		for i, col := range t.Columns {
+			f := goName(col.Name)
+			for seen[f] {
+				f += "_"
+			}
+			seen[f] = true
+			fields[i] = f
+		}
+
+		fmt.Fprintf(&body, "\n// %s is a row in the %s table.\ntype %s struct {\n", name, t.Name, name)
+		for i, col := range t.Columns {
+			typ := goType(c.dialect, col.Type)
+			usesTime = usesTime || typ == "time.Time"
+			fmt.Fprintf(&body, "\t%s %s `db:%q`\n", fields[i], typ, col.Name)
+		}
+		body.WriteString("}\n")
+
+		if c.tableName {
+			fmt.Fprintf(&body, "\n// TableName returns the name of the %s table.\n", t.Name)
+			fmt.Fprintf(&body, "func (%s) TableName() string { return %q }\n", name, t.Name)
+		}
+		if c.constants && len(t.Columns) > 0 {
+			fmt.Fprintf(&body, "\n// Columns of the %s table.\nconst (\n", t.Name)
+			for i, col := range t.Columns {
+				fmt.Fprintf(&body, "\t%s%s = %q\n", name, fields[i], col.Name)
+			}
+			body.WriteString(")\n")
+		}
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "// Code generated by sqlkit-gen. DO NOT EDIT.\n\npackage %s\n", c.pkg)
+	if usesTime {
+		out.WriteString("\nimport \"time\"\n")
+	}
+	out.Write(body.Bytes())
+	return format.Source(out.Bytes())
+}
+
+// goType maps a database column type to a Go type. Nullable columns use plain
+// types, NULL is decoded as the zero value. Types which are not known are
+// mapped to string, except on SQLite where they are mapped using its type
+// affinity rules.
+func goType(dialect db.Dialect, dbType string) string {
+	t := strings.ToLower(strings.TrimSpace(dbType))
+	if t == "tinyint(1)" {
+		return "bool"
+	}
+	if n := strings.IndexByte(t, '('); n >= 0 {
+		t = strings.TrimSpace(t[:n])
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(t, "unsigned"))
+
+	switch t {
+	case "bool", "boolean":
+		return "bool"
+	case "tinyint", "smallint", "mediumint", "int", "integer", "int2", "int4",
+		"serial", "smallserial", "year":
+		return "int"
+	case "bigint", "int8", "bigserial":
+		return "int64"
+	case "real", "float", "float4", "float8", "double", "double precision":
+		return "float64"
+	case "numeric", "decimal", "money":
+		return "string"
+	case "date", "datetime", "timestamp", "timestamptz",
+		"timestamp without time zone", "timestamp with time zone":
+		return "time.Time"
+	case "time", "timetz", "time without time zone", "time with time zone",
+		"interval", "point":
+		return "string"
+	case "bytea", "blob", "tinyblob", "mediumblob", "longblob", "binary", "varbinary":
+		return "[]byte"
+	}
+	if dialect != db.SQLite {
+		return "string"
+	}
+
+	switch {
+	case strings.Contains(t, "int"):
+		return "int64"
+	case strings.Contains(t, "char"), strings.Contains(t, "clob"), strings.Contains(t, "text"):
+		return "string"
+	case t == "" || strings.Contains(t, "blob"):
+		return "[]byte"
+	case strings.Contains(t, "real"), strings.Contains(t, "floa"), strings.Contains(t, "doub"):
+		return "float64"
+	}
+	return "string"
+}
+
+// initialisms are written in upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "HTML": true, "HTTP": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "URL": true, "UUID": true, "XML": true,
+}
+
+// goName converts a snake case database name to an exported Go name.
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var b strings.Builder
+	for _, w := range words {
+		if u := strings.ToUpper(w); initialisms[u] {
+			b.WriteString(u)
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	out := b.String()
+	if out == "" || !unicode.IsLetter([]rune(out)[0]) {
+		out = "X" + out
+	}
+	return out
+}
+
+// singular makes a plural table name singular for use as a struct name.
+func singular(name string) string {
+	switch {
+	case strings.HasSuffix(name, "ies") && len(name) > 3:
+		return name[:len(name)-3] + "y"
+	case strings.HasSuffix(name, "sses"), strings.HasSuffix(name, "xes"),
+		strings.HasSuffix(name, "ches"), strings.HasSuffix(name, "shes"):
+		return name[:len(name)-2]
+	case strings.HasSuffix(name, "s") && !strings.HasSuffix(name, "ss") &&
+		!strings.HasSuffix(name, "us") && len(name) > 1:
+		return name[:len(name)-1]
+	}
+	return name
+}
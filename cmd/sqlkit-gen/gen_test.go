@@ -0,0 +1,196 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/colinjfw/sqlkit/db"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerate(t *testing.T) {
+	d, err := db.Open("sqlite3", "file:gentest?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	require.NoError(t, d.Exec(ctx, db.Raw(`create table user_addresses (
+		id integer primary key,
+		user_id bigint not null,
+		street varchar(255),
+		created_at datetime,
+		table_name text
+	)`)).Err())
+	require.NoError(t, d.Exec(ctx, db.Raw(`create table categories (id int, score real, data blob)`)).Err())
+
+	schema, err := db.Inspect(ctx, d)
+	require.NoError(t, err)
+
+	src, err := generate(schema, config{pkg: "models", dialect: db.SQLite, tables: []string{"user_addresses"}, constants: true, tableName: true})
+	require.NoError(t, err)
+	require.Equal(t, `// Code generated by sqlkit-gen. DO NOT EDIT.
+
+package models
+
+import "time"
+
+// UserAddress is a row in the user_addresses table.
+type UserAddress struct {
+	ID         int       `+"`db:\"id\"`"+`
+	UserID     int64     `+"`db:\"user_id\"`"+`
+	Street     string    `+"`db:\"street\"`"+`
+	CreatedAt  time.Time `+"`db:\"created_at\"`"+`
+	TableName_ string    `+"`db:\"table_name\"`"+`
+}
+
+// TableName returns the name of the user_addresses table.
+func (UserAddress) TableName() string { return "user_addresses" }
+
+// Columns of the user_addresses table.
+const (
+	UserAddressID         = "id"
+	UserAddressUserID     = "user_id"
+	UserAddressStreet     = "street"
+	UserAddressCreatedAt  = "created_at"
+	UserAddressTableName_ = "table_name"
+)
+`, string(src))
+
+	src, err = generate(schema, config{pkg: "models", dialect: db.SQLite})
+	require.NoError(t, err)
+	require.Contains(t, string(src), `// Category is a row in the categories table.
+type Category struct {
+	ID    int     `+"`db:\"id\"`"+`
+	Score float64 `+"`db:\"score\"`"+`
+	Data  []byte  `+"`db:\"data\"`"+`
+}`)
+	require.NotContains(t, string(src), "TableName()")
+
+	_, err = generate(schema, config{pkg: "models", tables: []string{"missing"}})
+	require.Error(t, err)
+}
+
+// userAddress has the shape generated for the user_addresses table.
+type userAddress struct {
+	ID         int       `db:"id"`
+	UserID     int64     `db:"user_id"`
+	Street     string    `db:"street"`
+	CreatedAt  time.Time `db:"created_at"`
+	TableName_ string    `db:"table_name"`
+}
+
+func TestGenerate_RoundTrip(t *testing.T) {
+	d, err := db.Open("sqlite3", "file:genroundtrip?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	require.NoError(t, d.Exec(ctx, db.Raw(`create table user_addresses (
+		id integer primary key,
+		user_id bigint not null,
+		street varchar(255),
+		created_at datetime,
+		table_name text
+	)`)).Err())
+
+	schema, err := db.Inspect(ctx, d)
+	require.NoError(t, err)
+	src, err := generate(schema, config{pkg: "models", dialect: db.SQLite, tableName: true})
+	require.NoError(t, err)
+	typ := reflect.TypeOf(userAddress{})
+	for i := 0; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		field := regexp.MustCompile(`\t` + regexp.QuoteMeta(f.Name) + ` +` +
+			regexp.QuoteMeta(f.Type.String()) + ` +` + regexp.QuoteMeta("`"+string(f.Tag)+"`"))
+		require.Regexp(t, field, string(src))
+	}
+
+	in := userAddress{
+		ID:        1,
+		UserID:    2,
+		Street:    "1 Main St",
+		CreatedAt: time.Date(2018, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	require.NoError(t, d.Exec(ctx, d.Insert().Into("user_addresses").Record(in)).Err())
+	require.NoError(t, d.Exec(ctx, d.Insert().Into("user_addresses").
+		Row([]string{"id", "user_id"}, []interface{}{2, 3})).Err())
+
+	var out []userAddress
+	require.NoError(t, d.Query(ctx, d.Select("*").From("user_addresses").OrderBy("id")).Decode(&out))
+	require.Len(t, out, 2)
+	require.Equal(t, in.Street, out[0].Street)
+	require.True(t, in.CreatedAt.Equal(out[0].CreatedAt))
+	// NULL columns decode as the zero value.
+	require.Equal(t, userAddress{ID: 2, UserID: 3}, out[1])
+}
+
+func TestGoType(t *testing.T) {
+	for dbType, goTyp := range map[string]string{
+		"integer":                     "int",
+		"int(10) unsigned":            "int",
+		"BIGINT":                      "int64",
+		"bigint(20) unsigned":         "int64",
+		"tinyint(1)":                  "bool",
+		"boolean":                     "bool",
+		"character varying(255)":      "string",
+		"varchar(64)":                 "string",
+		"numeric(10,2)":               "string",
+		"double precision":            "float64",
+		"timestamp with time zone":    "time.Time",
+		"datetime(6)":                 "time.Time",
+		"time without time zone":      "string",
+		"interval":                    "string",
+		"point":                       "string",
+		"bytea":                       "[]byte",
+		"uuid":                        "string",
+		"integer[]":                   "string",
+		"enum('small','large')":       "string",
+		"timestamp(3) with time zone": "time.Time",
+	} {
+		require.Equal(t, goTyp, goType(db.Postgres, dbType), dbType)
+	}
+	// Unknown types use the type affinity rules on SQLite only.
+	for dbType, goTyp := range map[string]string{
+		"unsigned big int": "int64",
+		"nvarchar(10)":     "string",
+		"":                 "[]byte",
+		"floating point":   "int64",
+		"long double":      "float64",
+		"point":            "string",
+		"interval":         "string",
+	} {
+		require.Equal(t, goTyp, goType(db.SQLite, dbType), dbType)
+	}
+	require.Equal(t, "string", goType(db.MySQL, "unsigned big int"))
+}
+
+func TestGoName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"id":          "ID",
+		"user_id":     "UserID",
+		"html_body":   "HTMLBody",
+		"createdAt":   "CreatedAt",
+		"2fa_enabled": "X2faEnabled",
+	} {
+		require.Equal(t, expected, goName(name))
+	}
+	for name, expected := range map[string]string{
+		"Users":      "User",
+		"Categories": "Category",
+		"Addresses":  "Address",
+		"Boxes":      "Box",
+		"Status":     "Status",
+		"Access":     "Access",
+	} {
+		require.Equal(t, expected, singular(name))
+	}
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+// Command sqlkit-gen generates Go structs from the tables in a database.
+//
+// Each table is inspected using db.Inspect and written as a struct with db
+// tags matching the column names, so rows can be decoded and inserted using
+// the encoding package. Nullable columns use plain Go types and decode NULL as
+// the zero value. On MySQL date and datetime columns are generated as
+// time.Time, which only decodes when the DSN sets parseTime=true.
+//
+// Usage:
+//
+//	sqlkit-gen -driver postgres -dsn postgres://... -package models -out models/tables.go
+//	sqlkit-gen ... -tables users,groups -constants -tablename
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/colinjfw/sqlkit/db"
+)
+
+func main() {
+	driver := flag.String("driver", os.Getenv("SQLKIT_DRIVER"), "database driver: postgres, mysql or sqlite3")
+	dsn := flag.String("dsn", os.Getenv("SQLKIT_CONN"), "data source name")
+	out := flag.String("out", "", "output file, standard output if empty")
+	pkg := flag.String("package", "models", "package name of the generated file")
+	tables := flag.String("tables", "", "comma separated tables to generate, all tables if empty")
+	constants := flag.Bool("constants", false, "generate column name constants")
+	tableName := flag.Bool("tablename", false, "generate a TableName method")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: sqlkit-gen [flags]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	c := config{pkg: *pkg, constants: *constants, tableName: *tableName}
+	if *tables != "" {
+		c.tables = strings.Split(*tables, ",")
+	}
+	if err := run(*driver, *dsn, *out, c); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(driver, dsn, out string, c config) error {
+	d, err := db.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	schema, err := db.Inspect(context.Background(), d)
+	if err != nil {
+		return err
+	}
+	c.dialect = db.DialectOf(d)
+	src, err := generate(schema, c)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(out, src, 0644)
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+// Command sqlkit-migrate applies SQL migrations from a directory.
+//
+// Migrations are files named NNN_name.up.sql with an optional matching
+// NNN_name.down.sql, where NNN is the version. Files may contain multiple
+// statements separated by semicolons, including trigger and function bodies
+// written between BEGIN and END, see db.Split.
+//
+// Usage:
+//
+//	sqlkit-migrate -driver postgres -dsn postgres://... -dir migrations up
+//	sqlkit-migrate ... up VERSION
+//	sqlkit-migrate ... down VERSION
+//	sqlkit-migrate ... status
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/colinjfw/sqlkit/db"
+	"github.com/colinjfw/sqlkit/db/migrate"
+)
+
+func main() {
+	driver := flag.String("driver", os.Getenv("SQLKIT_DRIVER"), "database driver: postgres, mysql or sqlite3")
+	dsn := flag.String("dsn", os.Getenv("SQLKIT_CONN"), "data source name")
+	dir := flag.String("dir", "migrations", "directory containing migrations")
+	table := flag.String("table", "schema_migrations", "table recording applied migrations")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: sqlkit-migrate [flags] up [VERSION] | down VERSION | status\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := run(*driver, *dsn, *dir, *table, flag.Args()); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run(driver, dsn, dir, table string, args []string) error {
+	if len(args) == 0 {
+		flag.Usage()
+		return fmt.Errorf("sqlkit-migrate: missing command")
+	}
+	migrations, err := migrate.Load(os.DirFS(dir), ".")
+	if err != nil {
+		return err
+	}
+	d, err := db.Open(driver, dsn)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	m, err := migrate.New(d, migrations, migrate.WithTable(table))
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		err = m.Up(ctx)
+	case args[0] == "up" && len(args) == 2:
+		var version int64
+		if version, err = strconv.ParseInt(args[1], 10, 64); err == nil {
+			err = m.UpTo(ctx, version)
+		}
+	case args[0] == "down" && len(args) == 2:
+		var version int64
+		if version, err = strconv.ParseInt(args[1], 10, 64); err == nil {
+			err = m.Down(ctx, version)
+		}
+	case args[0] == "status" && len(args) == 1:
+	default:
+		flag.Usage()
+		return fmt.Errorf("sqlkit-migrate: unknown command %q", strings.Join(args, " "))
+	}
+	if err != nil {
+		return err
+	}
+	return status(ctx, m)
+}
+
+func status(ctx context.Context, m *migrate.Migrator) error {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return err
+	}
+	for _, s := range statuses {
+		applied := "pending"
+		if s.Applied {
+			applied = "applied " + s.AppliedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%d_%s\t%s\n", s.Version, s.Name, applied)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+// Aggregate helpers return SQL expressions which can be used as columns in
+// Select or in a Having clause:
+//
+//	Select("user_id", As(Count("*"), "n")).
+//		From("orders").
+//		GroupBy("user_id").
+//		Having(Gt(Sum("total"), 100))
+
+// Count returns COUNT(expr).
+func Count(expr string) string { return "COUNT(" + expr + ")" }
+
+// CountDistinct returns COUNT(DISTINCT expr).
+func CountDistinct(expr string) string { return "COUNT(DISTINCT " + expr + ")" }
+
+// Sum returns SUM(expr).
+func Sum(expr string) string { return "SUM(" + expr + ")" }
+
+// Avg returns AVG(expr).
+func Avg(expr string) string { return "AVG(" + expr + ")" }
+
+// Min returns MIN(expr).
+func Min(expr string) string { return "MIN(" + expr + ")" }
+
+// Max returns MAX(expr).
+func Max(expr string) string { return "MAX(" + expr + ")" }
+
+// As returns expr AS alias.
+func As(expr, alias string) string { return expr + " AS " + alias }
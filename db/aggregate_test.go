@@ -0,0 +1,60 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAggregate_SQL(t *testing.T) {
+	require.Equal(t, "COUNT(*)", Count("*"))
+	require.Equal(t, "COUNT(DISTINCT user_id)", CountDistinct("user_id"))
+	require.Equal(t, "SUM(total)", Sum("total"))
+	require.Equal(t, "AVG(total)", Avg("total"))
+	require.Equal(t, "MIN(total)", Min("total"))
+	require.Equal(t, "MAX(total) AS m", As(Max("total"), "m"))
+}
+
+func TestAggregate_SQLHaving(t *testing.T) {
+	testSQL(t,
+		"SELECT user_id,COUNT(*) AS n FROM orders WHERE total > $1 GROUP BY user_id HAVING ((SUM(total) > $2) AND COUNT(*) IN ($3, $4)) ORDER BY n",
+		[]interface{}{1, 100, 2, 3},
+		SelectStmt{dialect: Postgres}.
+			Select("user_id", As(Count("*"), "n")).
+			From("orders").
+			Where("total > ?", 1).
+			GroupBy("user_id").
+			Having(Gt(Sum("total"), 100)).
+			Having(Count("*")+" IN ?", []int{2, 3}).
+			OrderBy("n"),
+	)
+}
+
+func TestAggregate_Query(t *testing.T) {
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+
+		err := db.Exec(ctx, db.Insert().Into("users").
+			Columns("id").Values(1).Values(2).Values(3)).Err()
+		require.Nil(t, err)
+
+		var out []struct {
+			Even int `sql:"even"`
+			N    int `sql:"n"`
+		}
+		err = db.Query(ctx, db.Select(As("id % 2", "even"), As(Count("*"), "n")).
+			From("users").
+			GroupBy("id % 2").
+			Having(Gt(Count("*"), 1))).Decode(&out)
+		require.Nil(t, err)
+		require.Len(t, out, 1)
+		require.Equal(t, 1, out[0].Even)
+		require.Equal(t, 2, out[0].N)
+	})
+}
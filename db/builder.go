@@ -0,0 +1,66 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strings"
+
+// unboundSQL is implemented by statements which can render their SQL without
+// rebinding placeholders. This allows a statement to be nested inside another
+// before the outermost statement rebinds the query for its dialect.
+type unboundSQL interface {
+	unboundSQL() (string, []interface{}, error)
+}
+
+// builder accumulates SQL along with its bound values in the order that they
+// are written. Placeholders are written as '?' and rebound when the statement
+// is complete.
+type builder struct {
+	strings.Builder
+	values []interface{}
+	err    error
+	quote  bool // Quote identifiers.
+}
+
+// bind writes sql along with the values bound to its placeholders. Values
+// wrapped by Array keep their wrapper until the statement is complete, see
+// argValues.
+func (b *builder) bind(sql string, values ...interface{}) {
+	b.WriteString(sql)
+	b.values = append(b.values, values...)
+}
+
+// sql writes a nested SQL interface.
+func (b *builder) sql(s SQL) {
+	sql, values, err := unbound(s)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	b.bind(sql, values...)
+}
+
+// fail records the first error encountered while building.
+func (b *builder) fail(err error) {
+	if b.err == nil {
+		b.err = err
+	}
+}
+
+// result returns the built SQL, values and the first error encountered.
+func (b *builder) result() (string, []interface{}, error) {
+	if b.err != nil {
+		return "", nil, b.err
+	}
+	return b.String(), b.values, nil
+}
+
+// unbound renders SQL without rebinding placeholders if possible.
+func unbound(s SQL) (string, []interface{}, error) {
+	if u, ok := s.(unboundSQL); ok {
+		return u.unboundSQL()
+	}
+	return s.SQL()
+}
@@ -0,0 +1,44 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+// compound represents a select combined with a set operation.
+type compound struct {
+	op  string
+	sel SelectStmt
+}
+
+// compounds adds a select combined using the set operation.
+func (q SelectStmt) compounds(op string, sel SelectStmt) SelectStmt {
+	q.compound = append(q.compound[:len(q.compound):len(q.compound)], compound{op: op, sel: sel})
+	return q
+}
+
+// Union combines the select with another using UNION. The ORDER BY, LIMIT and
+// OFFSET configured on q apply to the combined result.
+func (q SelectStmt) Union(sel SelectStmt) SelectStmt {
+	return q.compounds("UNION", sel)
+}
+
+// UnionAll combines the select with another using UNION ALL.
+func (q SelectStmt) UnionAll(sel SelectStmt) SelectStmt {
+	return q.compounds("UNION ALL", sel)
+}
+
+// Intersect combines the select with another using INTERSECT. Like the other
+// set operations it applies to everything combined before it, the preceding
+// selects are parenthesized where INTERSECT would otherwise bind tighter. This
+// is not supported on MySQL before 8.0.31 and is rejected for the MySQL
+// dialect.
+func (q SelectStmt) Intersect(sel SelectStmt) SelectStmt {
+	return q.compounds("INTERSECT", sel)
+}
+
+// Except combines the select with another using EXCEPT. This is not supported
+// on MySQL before 8.0.31 and is rejected for the MySQL dialect.
+func (q SelectStmt) Except(sel SelectStmt) SelectStmt {
+	return q.compounds("EXCEPT", sel)
+}
@@ -0,0 +1,119 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompound_SQLUnion(t *testing.T) {
+	testSQL(t,
+		"SELECT id FROM users WHERE name = ? UNION SELECT id FROM admins WHERE name = ? ORDER BY id LIMIT 10",
+		[]interface{}{"a", "b"},
+		Select("id").
+			From("users").
+			Where("name = ?", "a").
+			Union(Select("id").From("admins").Where("name = ?", "b")).
+			OrderBy("id").
+			Limit(10),
+	)
+}
+
+func TestCompound_SQLPostgres(t *testing.T) {
+	d := New(WithDialect(Postgres))
+	testSQL(t,
+		"(SELECT id FROM users WHERE name = $1 UNION ALL SELECT id FROM admins WHERE name = $2) INTERSECT SELECT id FROM active WHERE since > $3 EXCEPT SELECT id FROM banned",
+		[]interface{}{"a", "b", 2019},
+		d.Select("id").
+			From("users").
+			Where("name = ?", "a").
+			UnionAll(d.Select("id").From("admins").Where("name = ?", "b")).
+			Intersect(d.Select("id").From("active").Where("since > ?", 2019)).
+			Except(d.Select("id").From("banned")),
+	)
+}
+
+func TestCompound_SQLPrecedence(t *testing.T) {
+	q := Select("id").
+		From("a").
+		Union(Select("id").From("b")).
+		Intersect(Select("id").From("c")).
+		Intersect(Select("id").From("d")).
+		Except(Select("id").From("e")).
+		Intersect(Select("id").From("f"))
+	testSQL(t,
+		"((SELECT id FROM a UNION SELECT id FROM b) INTERSECT SELECT id FROM c INTERSECT SELECT id FROM d "+
+			"EXCEPT SELECT id FROM e) INTERSECT SELECT id FROM f",
+		nil, q)
+
+	q.dialect = SQLite
+	testSQL(t,
+		"SELECT id FROM a UNION SELECT id FROM b INTERSECT SELECT id FROM c INTERSECT SELECT id FROM d "+
+			"EXCEPT SELECT id FROM e INTERSECT SELECT id FROM f",
+		nil, q)
+
+	testSQL(t,
+		"SELECT id FROM a INTERSECT SELECT id FROM b UNION SELECT id FROM c",
+		nil, Select("id").From("a").Intersect(Select("id").From("b")).Union(Select("id").From("c")))
+}
+
+func TestCompound_SQLParens(t *testing.T) {
+	testSQL(t,
+		"SELECT id FROM users UNION (SELECT id FROM admins ORDER BY id LIMIT 1)",
+		nil,
+		SelectStmt{dialect: MySQL}.
+			Select("id").
+			From("users").
+			Union(Select("id").From("admins").OrderBy("id").Limit(1)),
+	)
+
+	_, _, err := SelectStmt{dialect: SQLite}.
+		Select("id").
+		From("users").
+		Union(Select("id").From("admins").OrderBy("id").Limit(1)).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestCompound_SQLUnsupported(t *testing.T) {
+	_, _, err := SelectStmt{dialect: MySQL}.
+		Select("id").
+		From("users").
+		Intersect(Select("id").From("admins")).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, _, err = SelectStmt{dialect: MySQL}.
+		Select("id").
+		From("users").
+		Except(Select("id").From("admins")).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestCompound_Query(t *testing.T) {
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+
+		err := db.Exec(ctx, db.Insert().Into("users").
+			Values(1).Values(2).Values(3).Columns("id")).Err()
+		require.Nil(t, err)
+
+		var rows []int
+		err = db.Query(ctx, db.Select("id").
+			From("users").
+			Where("id = ?", 1).
+			UnionAll(db.Select("id").From("users").Where("id > ?", 1)).
+			OrderBy("id DESC").
+			Limit(2)).Decode(&rows)
+		require.Nil(t, err)
+		require.Equal(t, []int{3, 2}, rows)
+	})
+}
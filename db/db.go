@@ -26,6 +26,18 @@ var (
 	// ErrNestedTransactionsNotAllowed is returned when a nested transaction
 	// cannot be executed.
 	ErrNestedTransactionsNotAllowed = errors.New("sqlkit/db: nested transactions not allowed")
+	// ErrReturningUnsupported is returned when returning columns cannot be
+	// written back into records for the dialect.
+	ErrReturningUnsupported = errors.New("sqlkit/db: returning not supported by dialect")
+	// ErrUnsupported is returned when a statement uses a clause that cannot
+	// be rendered for the dialect.
+	ErrUnsupported = errors.New("sqlkit/db: unsupported by dialect")
+	// ErrLockOutsideTX is logged as a warning in debug mode when a select
+	// with a locking clause is run outside of a transaction.
+	ErrLockOutsideTX = errors.New("sqlkit/db: locking select outside of a transaction")
+	// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+	// or does not match the statement.
+	ErrInvalidCursor = errors.New("sqlkit/db: invalid cursor")
 )
 
 // StdLogger is a basic logger that uses the "log" package to log sql queries.
@@ -61,6 +73,23 @@ func WithEncoder(enc encoding.Encoder) Option {
 	return func(db *db) { db.encoder = enc }
 }
 
+// WithDebug enables debug mode. Warnings about likely mistakes, such as
+// running a locking select outside of a transaction, are passed to the logger
+// as an SQL which returns the warning as its error.
+func WithDebug() Option {
+	return func(db *db) { db.debug = true }
+}
+
+// WithIdentifierQuoting quotes table and column names in statements built by
+// the database using the dialect's quote character. Schema qualified names
+// are quoted per part. Keywords such as NULL and expressions which are not
+// plain identifiers, such as "count(*)", are left as they are. The columns
+// passed to predicate helpers such as Eq are quoted, but column names inside
+// raw WHERE strings are not.
+func WithIdentifierQuoting() Option {
+	return func(db *db) { db.quote = true }
+}
+
 // WithDisabledSavepoints will disable savepoints for a database.
 func WithDisabledSavepoints(enc encoding.Encoder) Option {
 	return func(db *db) { db.disableSavepoints = true }
@@ -98,6 +127,8 @@ func Open(driverName, dataSourceName string, opts ...Option) (DB, error) {
 		dialect = Postgres
 	case "mysql":
 		dialect = MySQL
+	case "sqlite3":
+		dialect = SQLite
 	default:
 		dialect = Generic
 	}
@@ -163,13 +194,15 @@ type DB interface {
 	// is a transaction then this will be used to run the query.
 	Query(context.Context, SQL) *Result
 	// Exec will execute an SQL query returning a result object. If the context
-	// is a transaction then this will be used to run the query.
+	// is a transaction then this will be used to run the query. Inserts that
+	// configure Returning will write the returned columns into their records.
 	Exec(context.Context, SQL) *Result
 	// Close will close the underlying DB connection.
 	Close() error
 	// Begin will create a new transaction. If the passed in context is a TX
 	// then a savepoint will be used. If the passed in context is cancellable it
-	// will monitor this context and rollback.
+	// will monitor this context and rollback. Options configured on the context
+	// using WithTxOptions are used when starting a new transaction.
 	Begin(context.Context) (TX, error)
 	// TX provides a safe way to execute a transaction. It ensures that if an
 	// error is raised Rollback() is called and if no error is raised Commit()
@@ -183,6 +216,30 @@ type DB interface {
 	Update(string) UpdateStmt
 	// Delete returns a DeleteStmt for the dialect.
 	Delete() DeleteStmt
+	// CreateTable returns a CreateTableStmt for the dialect.
+	CreateTable(name string) CreateTableStmt
+	// CreateIndex returns a CreateIndexStmt for the dialect.
+	CreateIndex(name, table string, cols ...string) CreateIndexStmt
+	// AlterTable returns an AlterTableStmt for the dialect.
+	AlterTable(name string) AlterTableStmt
+	// DropTable returns a DropTableStmt for the dialect.
+	DropTable(name string) DropTableStmt
+}
+
+// Dialecter is implemented by a DB which reports its SQL dialect. The DB
+// returned by New and Open implements it.
+type Dialecter interface {
+	// Dialect returns the SQL dialect of the database.
+	Dialect() Dialect
+}
+
+// DialectOf returns the dialect of the database, or Generic if it does not
+// implement Dialecter.
+func DialectOf(d DB) Dialect {
+	if d, ok := d.(Dialecter); ok {
+		return d.Dialect()
+	}
+	return Generic
 }
 
 // Result wraps a database/sql query result. It returns the same result for both
@@ -255,6 +312,15 @@ func (d *cache) close() (err error) {
 	return
 }
 
+type txOptionsKey struct{}
+
+// WithTxOptions returns a context which configures the options used by Begin
+// and TX when starting a new transaction, such as the isolation level or a
+// read only transaction.
+func WithTxOptions(ctx context.Context, opts *sql.TxOptions) context.Context {
+	return context.WithValue(ctx, txOptionsKey{}, opts)
+}
+
 type db struct {
 	*sql.DB
 
@@ -265,6 +331,8 @@ type db struct {
 	logger  func(SQL)
 
 	disableSavepoints bool
+	debug             bool
+	quote             bool
 }
 
 type tx struct {
@@ -389,7 +457,11 @@ func (d *db) Begin(ctx context.Context) (TX, error) {
 	}
 
 	d.logger(Raw("BEGIN"))
-	stx, err := d.DB.BeginTx(ctx, &sql.TxOptions{})
+	opts, _ := ctx.Value(txOptionsKey{}).(*sql.TxOptions)
+	if opts == nil {
+		opts = &sql.TxOptions{}
+	}
+	stx, err := d.DB.BeginTx(ctx, opts)
 	if err != nil {
 		return nil, err
 	}
@@ -403,6 +475,13 @@ func (d *db) Begin(ctx context.Context) (TX, error) {
 	return t, nil
 }
 
+func (d *db) prepare(ctx context.Context, sql string) (*sql.Stmt, error) {
+	if t, ok := ctx.(*tx); ok {
+		return t.cache.stmt(ctx, sql)
+	}
+	return d.cache.stmt(ctx, sql)
+}
+
 func (d *db) Query(ctx context.Context, q SQL) *Result {
 	defer d.logger(q)
 
@@ -410,15 +489,10 @@ func (d *db) Query(ctx context.Context, q SQL) *Result {
 	if err != nil {
 		return &Result{err: err}
 	}
-
-	var c *cache
-	if t, ok := ctx.(*tx); ok {
-		c = t.cache
-	} else {
-		c = d.cache
+	if d.debug {
+		d.warn(ctx, q, sql, args)
 	}
-
-	st, err := c.stmt(ctx, sql)
+	st, err := d.prepare(ctx, sql)
 	if err != nil {
 		return &Result{err: err}
 	}
@@ -426,20 +500,30 @@ func (d *db) Query(ctx context.Context, q SQL) *Result {
 	return &Result{Rows: rows, err: err, encoder: d.encoder}
 }
 
+// warn logs warnings for queries in debug mode.
+func (d *db) warn(ctx context.Context, q SQL, sql string, args []interface{}) {
+	if s, ok := q.(SelectStmt); ok && s.lock != "" {
+		if _, ok := ctx.(*tx); !ok {
+			d.logger(sqlHolder{sql: sql, args: args, err: ErrLockOutsideTX})
+		}
+	}
+}
+
 func (d *db) Exec(ctx context.Context, q SQL) *Result {
+	if i, ok := q.(InsertStmt); ok && i.returning != nil {
+		return d.insertReturning(ctx, i)
+	}
+	return d.exec(ctx, q)
+}
+
+func (d *db) exec(ctx context.Context, q SQL) *Result {
 	defer d.logger(q)
 
 	sql, args, err := q.SQL()
 	if err != nil {
 		return &Result{err: err}
 	}
-	var c *cache
-	if t, ok := ctx.(*tx); ok {
-		c = t.cache
-	} else {
-		c = d.cache
-	}
-	st, err := c.stmt(ctx, sql)
+	st, err := d.prepare(ctx, sql)
 	if err != nil {
 		return &Result{err: err}
 	}
@@ -459,20 +543,68 @@ func (d *db) Exec(ctx context.Context, q SQL) *Result {
 	}
 }
 
+// insertReturning executes an insert and writes the returning columns back into
+// the records. RETURNING is used when the dialect supports it, otherwise the ids
+// are computed from LastInsertId.
+func (d *db) insertReturning(ctx context.Context, i InsertStmt) *Result {
+	if err := i.checkReturning(); err != nil {
+		return &Result{err: err}
+	}
+	if !dialects[i.dialect].supportsReturning() {
+		r := d.exec(ctx, i)
+		if r.err == nil {
+			r.err = i.assignLastID(r.LastID)
+		}
+		return r
+	}
+
+	r := d.Query(ctx, i)
+	if r.err != nil {
+		return r
+	}
+	affected, err := i.scanReturning(r.Rows)
+	if cErr := r.Rows.Close(); err == nil {
+		err = cErr
+	}
+	return &Result{RowsAffected: affected, err: err, encoder: d.encoder}
+}
+
 func (d *db) Select(cols ...string) SelectStmt {
-	return SelectStmt{columns: cols, dialect: d.dialect}
+	return SelectStmt{dialect: d.dialect, quote: d.quote, encoder: d.encoder}.Select(cols...)
 }
 
 func (d *db) Delete() DeleteStmt {
-	return DeleteStmt{dialect: d.dialect}
+	return DeleteStmt{dialect: d.dialect, quote: d.quote}
 }
 
 func (d *db) Insert() InsertStmt {
-	return InsertStmt{dialect: d.dialect, encoder: d.encoder}
+	return InsertStmt{dialect: d.dialect, quote: d.quote, encoder: d.encoder}
 }
 
 func (d *db) Update(table string) UpdateStmt {
-	return UpdateStmt{dialect: d.dialect, table: table, encoder: d.encoder}
+	return UpdateStmt{dialect: d.dialect, quote: d.quote, table: table, encoder: d.encoder}
+}
+
+func (d *db) CreateTable(name string) CreateTableStmt {
+	return CreateTableStmt{dialect: d.dialect, quote: d.quote, table: name}
+}
+
+func (d *db) CreateIndex(name, table string, cols ...string) CreateIndexStmt {
+	q := CreateIndex(name, table, cols...)
+	q.dialect, q.quote = d.dialect, d.quote
+	return q
+}
+
+func (d *db) AlterTable(name string) AlterTableStmt {
+	return AlterTableStmt{dialect: d.dialect, quote: d.quote, table: name}
+}
+
+func (d *db) DropTable(name string) DropTableStmt {
+	return DropTableStmt{dialect: d.dialect, quote: d.quote, table: name}
+}
+
+func (d *db) Dialect() Dialect {
+	return d.dialect
 }
 
 func (d *db) Close() (err error) {
@@ -43,6 +43,19 @@ func wrap(t *testing.T, cb func(db DB)) {
 	require.Nil(t, testdb.Close())
 }
 
+// serialTable returns a create table statement with an auto increment id for
+// the dialect under test.
+func serialTable(d DB, name string) Raw {
+	switch d.(*db).dialect {
+	case Postgres:
+		return Raw("create table " + name + " (id serial primary key, name text)")
+	case MySQL:
+		return Raw("create table " + name + " (id int auto_increment primary key, name text)")
+	default:
+		return Raw("create table " + name + " (id integer primary key autoincrement, name text)")
+	}
+}
+
 func testSQL(t *testing.T, expected string, values []interface{}, sql SQL) {
 	spew.Dump(sql)
 	str, vals, err := sql.SQL()
@@ -60,6 +73,13 @@ func TestDB_Query(t *testing.T) {
 	})
 }
 
+func TestDB_DialectOf(t *testing.T) {
+	d := New(WithDialect(Postgres))
+	require.Equal(t, Postgres, DialectOf(d))
+	// A wrapper which only implements DB has no dialect.
+	require.Equal(t, Generic, DialectOf(struct{ DB }{d}))
+}
+
 func TestDB_Insert(t *testing.T) {
 	wrap(t, func(db DB) {
 		ctx := context.Background()
@@ -127,6 +147,90 @@ func TestDB_Marshal(t *testing.T) {
 	})
 }
 
+func TestDB_InsertReturning(t *testing.T) {
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+
+		db.Exec(ctx, Raw("drop table serials"))
+		err := db.Exec(ctx, serialTable(db, "serials")).Err()
+		require.Nil(t, err)
+		defer db.Exec(ctx, Raw("drop table serials"))
+
+		type serial struct {
+			ID   int    `sql:"id"`
+			Name string `sql:"name"`
+		}
+
+		one := &serial{Name: "one"}
+		err = db.Exec(ctx, db.Insert().
+			Into("serials").
+			Record(one, "name").
+			Returning("id")).Err()
+		require.Nil(t, err)
+		require.Equal(t, 1, one.ID)
+
+		more := []serial{{Name: "two"}, {Name: "three"}}
+		r := db.Exec(ctx, db.Insert().
+			Into("serials").
+			Records(more, "name").
+			Returning("id"))
+		require.Nil(t, r.Err())
+		require.Equal(t, int64(2), r.RowsAffected)
+		require.Equal(t, 2, more[0].ID)
+		require.Equal(t, 3, more[1].ID)
+	})
+}
+
+func TestDB_InsertReturningNonPtr(t *testing.T) {
+	wrap(t, func(db DB) {
+		err := db.Exec(context.Background(), db.Insert().
+			Into("users").
+			Record(struct {
+				ID int `sql:"id"`
+			}{ID: 1}).
+			Returning("id")).Err()
+		require.NotNil(t, err)
+
+		var count int
+		err = db.Query(context.Background(), Raw("SELECT COUNT(*) FROM users")).Decode(&count)
+		require.Nil(t, err)
+		require.Equal(t, 0, count)
+	})
+}
+
+func TestDB_InsertFromSelect(t *testing.T) {
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+
+		err := db.Exec(ctx, db.Insert().Into("users").Value("id", 1)).Err()
+		require.Nil(t, err)
+
+		err = db.Exec(ctx, db.Insert().
+			Into("users").
+			Columns("id").
+			FromSelect(db.Select("id + 10").From("users").Where("id = ?", 1))).Err()
+		require.Nil(t, err)
+
+		var ids []int
+		err = db.Query(ctx, db.Select("id").From("users").OrderBy("id")).Decode(&ids)
+		require.Nil(t, err)
+		require.Equal(t, []int{1, 11}, ids)
+	})
+}
+
+func TestDB_WithRecursive(t *testing.T) {
+	wrap(t, func(db DB) {
+		var rows []int
+		err := db.Query(context.Background(), db.Select("x").
+			WithRecursive("cnt", []string{"x"}, RawWithValues(
+				"SELECT 1 UNION ALL SELECT x + 1 FROM cnt WHERE x < ?", 5,
+			)).
+			From("cnt")).Decode(&rows)
+		require.Nil(t, err)
+		require.Equal(t, []int{1, 2, 3, 4, 5}, rows)
+	})
+}
+
 func TestDB_TxBegin(t *testing.T) {
 	wrap(t, func(db DB) {
 		ctx, err := db.Begin(context.Background())
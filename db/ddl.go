@@ -0,0 +1,466 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strconv"
+
+// ColumnType is the type of a column in a DDL statement. The predefined types
+// are rendered using the matching type for the dialect, any other value is
+// written as is.
+type ColumnType string
+
+// Column types which are portable between dialects. Serial and BigSerial are
+// auto incrementing integers which are rendered as the primary key of the
+// table, for example SERIAL PRIMARY KEY on Postgres, INT AUTO_INCREMENT
+// PRIMARY KEY on MySQL and INTEGER PRIMARY KEY AUTOINCREMENT on SQLite, so a
+// table with a serial column cannot have another primary key. Timestamp is a
+// DATETIME on MySQL, without fractional seconds, so that it accepts a
+// CURRENT_TIMESTAMP default.
+const (
+	Serial    ColumnType = "SERIAL"
+	BigSerial ColumnType = "BIGSERIAL"
+	Integer   ColumnType = "INTEGER"
+	BigInt    ColumnType = "BIGINT"
+	Boolean   ColumnType = "BOOLEAN"
+	Float     ColumnType = "DOUBLE PRECISION"
+	Text      ColumnType = "TEXT"
+	Bytes     ColumnType = "BLOB"
+	Timestamp ColumnType = "TIMESTAMP"
+)
+
+// Varchar returns a VARCHAR type with a maximum length.
+func Varchar(n int) ColumnType { return ColumnType("VARCHAR(" + strconv.Itoa(n) + ")") }
+
+// Dialect specific names for the portable column types.
+var (
+	genericTypes = map[ColumnType]string{
+		Serial:    "INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+		BigSerial: "BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY",
+	}
+	postgresTypes = map[ColumnType]string{
+		Serial:    "SERIAL PRIMARY KEY",
+		BigSerial: "BIGSERIAL PRIMARY KEY",
+		Bytes:     "BYTEA",
+		Timestamp: "TIMESTAMPTZ",
+	}
+	mysqlTypes = map[ColumnType]string{
+		Serial:    "INT AUTO_INCREMENT PRIMARY KEY",
+		BigSerial: "BIGINT AUTO_INCREMENT PRIMARY KEY",
+		Float:     "DOUBLE",
+		Timestamp: "DATETIME",
+	}
+	sqliteTypes = map[ColumnType]string{
+		Serial:    "INTEGER PRIMARY KEY AUTOINCREMENT",
+		BigSerial: "INTEGER PRIMARY KEY AUTOINCREMENT",
+		Float:     "REAL",
+		Timestamp: "DATETIME",
+	}
+)
+
+// ColumnOption configures a column in CreateTable or AddColumn.
+type ColumnOption func(c *columnDef)
+
+// NotNull adds a NOT NULL constraint to the column.
+func NotNull() ColumnOption {
+	return func(c *columnDef) { c.notNull = true }
+}
+
+// PrimaryKey makes the column the primary key. Use CreateTableStmt.PrimaryKey
+// for a primary key with multiple columns.
+func PrimaryKey() ColumnOption {
+	return func(c *columnDef) { c.primaryKey = true }
+}
+
+// Unique adds a UNIQUE constraint to the column.
+func Unique() ColumnOption {
+	return func(c *columnDef) { c.unique = true }
+}
+
+// Default configures the default value of the column. The value is written as
+// a literal for the dialect, or as is if it implements SQL, such as
+// Raw("CURRENT_TIMESTAMP"). Unlike Interpolate long values are not truncated.
+func Default(value interface{}) ColumnOption {
+	return func(c *columnDef) { c.def, c.hasDefault = value, true }
+}
+
+// References adds a foreign key from the column to a column of another table.
+// Use CreateTableStmt.ForeignKey for multiple columns or referential actions.
+func References(table, column string) ColumnOption {
+	return func(c *columnDef) {
+		c.ref = &ForeignKey{RefTable: table, RefColumns: []string{column}}
+	}
+}
+
+type columnDef struct {
+	name       string
+	typ        ColumnType
+	notNull    bool
+	primaryKey bool
+	unique     bool
+	def        interface{}
+	hasDefault bool
+	ref        *ForeignKey
+}
+
+func (c columnDef) serial() bool {
+	return c.typ == Serial || c.typ == BigSerial
+}
+
+func newColumnDef(name string, typ ColumnType, opts []ColumnOption) columnDef {
+	c := columnDef{name: name, typ: typ}
+	for _, o := range opts {
+		o(&c)
+	}
+	if c.ref != nil {
+		c.ref.Columns = []string{name}
+	}
+	return c
+}
+
+// CreateTable returns a new CreateTableStmt.
+func CreateTable(name string) CreateTableStmt { return CreateTableStmt{table: name} }
+
+// CreateTableStmt represents a CREATE TABLE in SQL.
+type CreateTableStmt struct {
+	dialect     Dialect
+	quote       bool
+	table       string
+	ifNotExists bool
+	columns     []columnDef
+	primaryKey  []string
+	unique      [][]string
+	foreignKeys []ForeignKey
+}
+
+// IfNotExists renders CREATE TABLE IF NOT EXISTS.
+func (q CreateTableStmt) IfNotExists() CreateTableStmt {
+	q.ifNotExists = true
+	return q
+}
+
+// Column adds a column to the table.
+func (q CreateTableStmt) Column(name string, typ ColumnType, opts ...ColumnOption) CreateTableStmt {
+	q.columns = append(q.columns[:len(q.columns):len(q.columns)], newColumnDef(name, typ, opts))
+	return q
+}
+
+// PrimaryKey configures a primary key constraint over the columns.
+func (q CreateTableStmt) PrimaryKey(cols ...string) CreateTableStmt {
+	q.primaryKey = append([]string(nil), cols...)
+	return q
+}
+
+// Unique adds a unique constraint over the columns.
+func (q CreateTableStmt) Unique(cols ...string) CreateTableStmt {
+	q.unique = append(q.unique[:len(q.unique):len(q.unique)], append([]string(nil), cols...))
+	return q
+}
+
+// ForeignKey adds a foreign key constraint. Name, OnUpdate and OnDelete are
+// optional, OnUpdate and OnDelete are written as is, for example "CASCADE".
+func (q CreateTableStmt) ForeignKey(fk ForeignKey) CreateTableStmt {
+	q.foreignKeys = append(q.foreignKeys[:len(q.foreignKeys):len(q.foreignKeys)], fk)
+	return q
+}
+
+// SQL implements the SQL interface.
+func (q CreateTableStmt) SQL() (string, []interface{}, error) {
+	if q.table == "" || len(q.columns) == 0 {
+		return "", nil, ErrStatementInvalid
+	}
+	// Serial columns are rendered as the primary key, so the table can have
+	// no other.
+	keys := 0
+	if len(q.primaryKey) > 0 {
+		keys++
+	}
+	for _, c := range q.columns {
+		if c.primaryKey || c.serial() {
+			keys++
+		}
+	}
+	if keys > 1 {
+		return "", nil, ErrStatementInvalid
+	}
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].createTable(b, q)
+	return b.result()
+}
+
+// CreateIndex returns a new CreateIndexStmt.
+func CreateIndex(name, table string, cols ...string) CreateIndexStmt {
+	return CreateIndexStmt{name: name, table: table, columns: append([]string(nil), cols...)}
+}
+
+// CreateIndexStmt represents a CREATE INDEX in SQL.
+type CreateIndexStmt struct {
+	dialect     Dialect
+	quote       bool
+	name        string
+	table       string
+	columns     []string
+	unique      bool
+	ifNotExists bool
+}
+
+// Unique renders CREATE UNIQUE INDEX.
+func (q CreateIndexStmt) Unique() CreateIndexStmt {
+	q.unique = true
+	return q
+}
+
+// IfNotExists renders CREATE INDEX IF NOT EXISTS. MySQL does not support this.
+func (q CreateIndexStmt) IfNotExists() CreateIndexStmt {
+	q.ifNotExists = true
+	return q
+}
+
+// SQL implements the SQL interface.
+func (q CreateIndexStmt) SQL() (string, []interface{}, error) {
+	if q.name == "" || q.table == "" || len(q.columns) == 0 {
+		return "", nil, ErrStatementInvalid
+	}
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].createIndex(b, q)
+	return b.result()
+}
+
+// AlterTable returns a new AlterTableStmt.
+func AlterTable(name string) AlterTableStmt { return AlterTableStmt{table: name} }
+
+// AlterTableStmt represents an ALTER TABLE in SQL. SQLite only supports a
+// single action in each statement, does not support DROP COLUMN and cannot add
+// a column which is UNIQUE or a PRIMARY KEY.
+type AlterTableStmt struct {
+	dialect Dialect
+	quote   bool
+	table   string
+	actions []alterAction
+}
+
+type alterAction struct {
+	add  *columnDef
+	drop string
+}
+
+// AddColumn adds a column to the table.
+func (q AlterTableStmt) AddColumn(name string, typ ColumnType, opts ...ColumnOption) AlterTableStmt {
+	c := newColumnDef(name, typ, opts)
+	q.actions = append(q.actions[:len(q.actions):len(q.actions)], alterAction{add: &c})
+	return q
+}
+
+// DropColumn drops a column from the table.
+func (q AlterTableStmt) DropColumn(name string) AlterTableStmt {
+	q.actions = append(q.actions[:len(q.actions):len(q.actions)], alterAction{drop: name})
+	return q
+}
+
+// SQL implements the SQL interface.
+func (q AlterTableStmt) SQL() (string, []interface{}, error) {
+	if q.table == "" || len(q.actions) == 0 {
+		return "", nil, ErrStatementInvalid
+	}
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].alterTable(b, q)
+	return b.result()
+}
+
+// DropTable returns a new DropTableStmt.
+func DropTable(name string) DropTableStmt { return DropTableStmt{table: name} }
+
+// DropTableStmt represents a DROP TABLE in SQL.
+type DropTableStmt struct {
+	dialect  Dialect
+	quote    bool
+	table    string
+	ifExists bool
+}
+
+// IfExists renders DROP TABLE IF EXISTS.
+func (q DropTableStmt) IfExists() DropTableStmt {
+	q.ifExists = true
+	return q
+}
+
+// SQL implements the SQL interface.
+func (q DropTableStmt) SQL() (string, []interface{}, error) {
+	if q.table == "" {
+		return "", nil, ErrStatementInvalid
+	}
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].dropTable(b, q)
+	return b.result()
+}
+
+func (m genericMapper) createTable(b *builder, q CreateTableStmt) {
+	b.WriteString("CREATE TABLE ")
+	if q.ifNotExists {
+		b.WriteString("IF NOT EXISTS ")
+	}
+	m.ident(b, q.table)
+	b.WriteString(" (")
+	for i, c := range q.columns {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		m.column(b, q.dialect, c)
+	}
+	if len(q.primaryKey) > 0 {
+		b.WriteString(", PRIMARY KEY (")
+		m.idents(b, q.primaryKey, ", ")
+		b.WriteString(")")
+	}
+	for _, cols := range q.unique {
+		b.WriteString(", UNIQUE (")
+		m.idents(b, cols, ", ")
+		b.WriteString(")")
+	}
+	for _, c := range q.columns {
+		if c.ref != nil {
+			b.WriteString(", ")
+			m.foreignKey(b, *c.ref)
+		}
+	}
+	for _, fk := range q.foreignKeys {
+		b.WriteString(", ")
+		m.foreignKey(b, fk)
+	}
+	b.WriteString(")")
+}
+
+func (m genericMapper) createIndex(b *builder, q CreateIndexStmt) {
+	b.WriteString("CREATE ")
+	if q.unique {
+		b.WriteString("UNIQUE ")
+	}
+	b.WriteString("INDEX ")
+	if q.ifNotExists {
+		if !m.indexIfNotExists {
+			b.fail(unsupported("CREATE INDEX IF NOT EXISTS"))
+			return
+		}
+		b.WriteString("IF NOT EXISTS ")
+	}
+	m.ident(b, q.name)
+	b.WriteString(" ON ")
+	m.ident(b, q.table)
+	b.WriteString(" (")
+	m.idents(b, q.columns, ", ")
+	b.WriteString(")")
+}
+
+func (m genericMapper) alterTable(b *builder, q AlterTableStmt) {
+	if len(q.actions) > 1 && !m.alterActions {
+		b.fail(unsupported("multiple ALTER TABLE actions"))
+		return
+	}
+	b.WriteString("ALTER TABLE ")
+	m.ident(b, q.table)
+	for i, a := range q.actions {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		if a.add == nil {
+			if !m.dropColumn {
+				b.fail(unsupported("ALTER TABLE DROP COLUMN"))
+				return
+			}
+			b.WriteString(" DROP COLUMN ")
+			m.ident(b, a.drop)
+			continue
+		}
+		if !m.addConstraints && (a.add.unique || a.add.primaryKey || a.add.serial()) {
+			b.fail(unsupported("ALTER TABLE ADD COLUMN with UNIQUE or PRIMARY KEY"))
+			return
+		}
+		b.WriteString(" ADD COLUMN ")
+		m.column(b, q.dialect, *a.add)
+		if a.add.ref == nil {
+			continue
+		}
+		// Dialects which cannot add constraints in the same statement only
+		// support the foreign key as a column constraint.
+		if !m.alterActions {
+			b.WriteString(" REFERENCES ")
+			m.ident(b, a.add.ref.RefTable)
+			b.WriteString(" (")
+			m.idents(b, a.add.ref.RefColumns, ", ")
+			b.WriteString(")")
+			continue
+		}
+		b.WriteString(", ADD ")
+		m.foreignKey(b, *a.add.ref)
+	}
+}
+
+func (m genericMapper) dropTable(b *builder, q DropTableStmt) {
+	b.WriteString("DROP TABLE ")
+	if q.ifExists {
+		b.WriteString("IF EXISTS ")
+	}
+	m.ident(b, q.table)
+}
+
+// column writes a column definition with its type and column constraints.
+func (m genericMapper) column(b *builder, dialect Dialect, c columnDef) {
+	m.ident(b, c.name)
+	b.WriteString(" ")
+	typ, ok := m.types[c.typ]
+	if !ok {
+		typ = string(c.typ)
+	}
+	b.WriteString(typ)
+	if c.notNull {
+		b.WriteString(" NOT NULL")
+	}
+	if c.hasDefault {
+		var def string
+		var err error
+		if s, ok := c.def.(SQL); ok {
+			def, err = interpolate(dialect, s, 0)
+		} else {
+			def, err = literal(dialect, c.def, 0)
+		}
+		if err != nil {
+			b.fail(err)
+			return
+		}
+		b.WriteString(" DEFAULT " + def)
+	}
+	if c.unique {
+		b.WriteString(" UNIQUE")
+	}
+	if c.primaryKey && !c.serial() {
+		b.WriteString(" PRIMARY KEY")
+	}
+}
+
+// foreignKey writes a FOREIGN KEY table constraint.
+func (m genericMapper) foreignKey(b *builder, fk ForeignKey) {
+	if len(fk.Columns) == 0 || len(fk.Columns) != len(fk.RefColumns) || fk.RefTable == "" {
+		b.fail(ErrStatementInvalid)
+		return
+	}
+	if fk.Name != "" {
+		b.WriteString("CONSTRAINT ")
+		m.ident(b, fk.Name)
+		b.WriteString(" ")
+	}
+	b.WriteString("FOREIGN KEY (")
+	m.idents(b, fk.Columns, ", ")
+	b.WriteString(") REFERENCES ")
+	m.ident(b, fk.RefTable)
+	b.WriteString(" (")
+	m.idents(b, fk.RefColumns, ", ")
+	b.WriteString(")")
+	if fk.OnDelete != "" {
+		b.WriteString(" ON DELETE " + fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		b.WriteString(" ON UPDATE " + fk.OnUpdate)
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func usersTable(d DB) CreateTableStmt {
+	return d.CreateTable("users").
+		IfNotExists().
+		Column("id", Serial).
+		Column("email", Varchar(255), NotNull(), Unique()).
+		Column("active", Boolean, NotNull(), Default(true)).
+		Column("created_at", Timestamp, Default(Raw("CURRENT_TIMESTAMP")))
+}
+
+func TestCreateTable_SQL(t *testing.T) {
+	testSQL(t,
+		"CREATE TABLE IF NOT EXISTS users (id SERIAL PRIMARY KEY, email VARCHAR(255) NOT NULL UNIQUE, "+
+			"active BOOLEAN NOT NULL DEFAULT TRUE, created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP)",
+		nil, usersTable(New(WithDialect(Postgres))))
+	testSQL(t,
+		"CREATE TABLE IF NOT EXISTS users (id INT AUTO_INCREMENT PRIMARY KEY, email VARCHAR(255) NOT NULL UNIQUE, "+
+			"active BOOLEAN NOT NULL DEFAULT TRUE, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)",
+		nil, usersTable(New(WithDialect(MySQL))))
+	testSQL(t,
+		"CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY AUTOINCREMENT, email VARCHAR(255) NOT NULL UNIQUE, "+
+			"active BOOLEAN NOT NULL DEFAULT 1, created_at DATETIME DEFAULT CURRENT_TIMESTAMP)",
+		nil, usersTable(New(WithDialect(SQLite))))
+}
+
+func TestCreateTable_MySQLTimestamp(t *testing.T) {
+	testSQL(t,
+		"CREATE TABLE events (created_at DATETIME NOT NULL DEFAULT current_timestamp, "+
+			"updated_at DATETIME DEFAULT CURRENT_TIMESTAMP)",
+		nil, New(WithDialect(MySQL)).CreateTable("events").
+			Column("created_at", Timestamp, NotNull(), Default(Raw("current_timestamp"))).
+			Column("updated_at", Timestamp, Default(Raw("CURRENT_TIMESTAMP"))))
+}
+
+func TestCreateTable_Constraints(t *testing.T) {
+	q := CreateTable("members").
+		Column("account_id", BigInt, NotNull()).
+		Column("user_id", Integer, References("users", "id")).
+		Column("role", Text, Default("member")).
+		PrimaryKey("account_id", "user_id").
+		Unique("user_id", "role").
+		ForeignKey(ForeignKey{
+			Name:       "members_account",
+			Columns:    []string{"account_id"},
+			RefTable:   "accounts",
+			RefColumns: []string{"id"},
+			OnDelete:   "CASCADE",
+		})
+	testSQL(t,
+		"CREATE TABLE members (account_id BIGINT NOT NULL, user_id INTEGER, role TEXT DEFAULT 'member', "+
+			"PRIMARY KEY (account_id, user_id), UNIQUE (user_id, role), "+
+			"FOREIGN KEY (user_id) REFERENCES users (id), "+
+			"CONSTRAINT members_account FOREIGN KEY (account_id) REFERENCES accounts (id) ON DELETE CASCADE)",
+		nil, q)
+
+	q.quote = true
+	testSQL(t,
+		`CREATE TABLE "members" ("account_id" BIGINT NOT NULL, "user_id" INTEGER, "role" TEXT DEFAULT 'member', `+
+			`PRIMARY KEY ("account_id", "user_id"), UNIQUE ("user_id", "role"), `+
+			`FOREIGN KEY ("user_id") REFERENCES "users" ("id"), `+
+			`CONSTRAINT "members_account" FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE)`,
+		nil, q)
+
+	_, _, err := CreateTable("members").SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+	for _, q := range []CreateTableStmt{
+		CreateTable("members").Column("id", Serial).PrimaryKey("id"),
+		CreateTable("members").Column("id", BigSerial).Column("key", Text, PrimaryKey()),
+		CreateTable("members").Column("id", Integer, PrimaryKey()).PrimaryKey("id"),
+	} {
+		_, _, err = q.SQL()
+		require.Equal(t, ErrStatementInvalid, err)
+	}
+	testSQL(t, "CREATE TABLE members (id INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY)", nil,
+		CreateTable("members").Column("id", Serial, PrimaryKey()))
+	_, _, err = CreateTable("members").
+		Column("id", Integer).
+		ForeignKey(ForeignKey{Columns: []string{"id"}, RefTable: "users"}).
+		SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+}
+
+func TestCreateIndex_SQL(t *testing.T) {
+	testSQL(t, "CREATE INDEX users_email ON users (email, id)", nil,
+		CreateIndex("users_email", "users", "email", "id"))
+	testSQL(t, "CREATE UNIQUE INDEX IF NOT EXISTS users_email ON users (email)", nil,
+		New(WithDialect(Postgres)).CreateIndex("users_email", "users", "email").Unique().IfNotExists())
+
+	_, _, err := New(WithDialect(MySQL)).CreateIndex("users_email", "users", "email").IfNotExists().SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestAlterTable_SQL(t *testing.T) {
+	q := AlterTable("users").
+		AddColumn("group_id", BigInt, References("groups", "id")).
+		DropColumn("name")
+	testSQL(t, "ALTER TABLE users ADD COLUMN group_id BIGINT, ADD FOREIGN KEY (group_id) REFERENCES groups (id), "+
+		"DROP COLUMN name", nil, q)
+
+	q.dialect = SQLite
+	_, _, err := q.SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+	sqlite := New(WithDialect(SQLite))
+	testSQL(t, "ALTER TABLE users ADD COLUMN group_id BIGINT REFERENCES groups (id)", nil,
+		sqlite.AlterTable("users").AddColumn("group_id", BigInt, References("groups", "id")))
+	for _, q := range []AlterTableStmt{
+		sqlite.AlterTable("users").DropColumn("name"),
+		sqlite.AlterTable("users").AddColumn("email", Text, Unique()),
+		sqlite.AlterTable("users").AddColumn("key", Text, PrimaryKey()),
+		sqlite.AlterTable("users").AddColumn("id", Serial),
+	} {
+		_, _, err = q.SQL()
+		require.True(t, errors.Is(err, ErrUnsupported))
+	}
+}
+
+func TestDDL_DefaultNotTruncated(t *testing.T) {
+	long := strings.Repeat("x", 1100)
+	testSQL(t, "CREATE TABLE t (a TEXT DEFAULT '"+long+"', b TEXT DEFAULT ('"+long+"'))", nil,
+		CreateTable("t").
+			Column("a", Text, Default(long)).
+			Column("b", Text, Default(RawWithValues("(?)", long))))
+	testSQL(t, "CREATE TABLE t (a BLOB DEFAULT X'"+strings.Repeat("ab", 600)+"')", nil,
+		CreateTable("t").Column("a", Bytes, Default([]byte(strings.Repeat("\xab", 600)))))
+}
+
+func TestDropTable_SQL(t *testing.T) {
+	testSQL(t, "DROP TABLE users", nil, DropTable("users"))
+	testSQL(t, "DROP TABLE IF EXISTS users", nil, DropTable("users").IfExists())
+}
+
+func TestDDL_Live(t *testing.T) {
+	d, err := Open("sqlite3", "file:ddltest?mode=memory&cache=shared")
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	for _, q := range []SQL{
+		usersTable(d),
+		d.CreateTable("posts").
+			Column("id", Serial).
+			Column("user_id", Integer, NotNull(), References("users", "id")).
+			Column("title", Text, Default("untitled")),
+		d.CreateIndex("posts_title", "posts", "title"),
+		d.AlterTable("posts").AddColumn("score", Float, Default(0.5)),
+	} {
+		require.Nil(t, d.Exec(ctx, q).Err())
+	}
+
+	schema, err := Inspect(ctx, d)
+	require.Nil(t, err)
+	posts, ok := schema.Table("posts")
+	require.True(t, ok)
+	require.Equal(t, []Column{
+		{Name: "id", Type: "INTEGER"},
+		{Name: "user_id", Type: "INTEGER"},
+		{Name: "title", Type: "TEXT", Nullable: true, Default: "'untitled'"},
+		{Name: "score", Type: "REAL", Nullable: true, Default: "0.5"},
+	}, posts.Columns)
+	require.Equal(t, []string{"id"}, posts.PrimaryKey)
+	require.Equal(t, []Index{{Name: "posts_title", Columns: []string{"title"}}}, posts.Indexes)
+	require.Equal(t, "users", posts.ForeignKeys[0].RefTable)
+
+	require.Nil(t, d.Exec(ctx, d.DropTable("posts")).Err())
+	require.Nil(t, d.Exec(ctx, d.DropTable("posts").IfExists()).Err())
+	schema, err = Inspect(ctx, d)
+	require.Nil(t, err)
+	require.Len(t, schema.Tables, 1)
+}
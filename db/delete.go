@@ -11,6 +11,8 @@ func Delete() DeleteStmt { return DeleteStmt{} }
 // DeleteStmt represents a DELETE in sql.
 type DeleteStmt struct {
 	dialect Dialect
+	quote   bool
+	using   []string
 	sel     SelectStmt
 }
 
@@ -20,6 +22,38 @@ func (q DeleteStmt) From(table string) DeleteStmt {
 	return q
 }
 
+// Using adds a table which can be referenced in the WHERE clause to select the
+// rows to delete. This renders as DELETE ... USING on Postgres and as a
+// multiple table DELETE on MySQL. SQLite does not support this.
+func (q DeleteStmt) Using(table string) DeleteStmt {
+	q.using = append(q.using[:len(q.using):len(q.using)], table)
+	return q
+}
+
+// Join adds an inner join to the delete. Only rows from the table configured
+// in From are deleted. On Postgres the table is added to USING and the join
+// condition is moved into the WHERE clause. SQLite does not support this.
+func (q DeleteStmt) Join(table, on string, values ...interface{}) DeleteStmt {
+	q.sel = q.sel.joins("INNER", table, on, values...)
+	return q
+}
+
+// OrderBy configures the ORDER BY clause. This is only supported on MySQL for
+// single table deletes. SQLite only supports this when compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, which the go-sqlite3 driver is not, so it
+// is rejected.
+func (q DeleteStmt) OrderBy(orderBy ...string) DeleteStmt {
+	q.sel = q.sel.OrderBy(orderBy...)
+	return q
+}
+
+// Limit configures the LIMIT clause. This is only supported on MySQL for single
+// table deletes.
+func (q DeleteStmt) Limit(limit int) DeleteStmt {
+	q.sel = q.sel.Limit(limit)
+	return q
+}
+
 // Where configures the WHERE clause in a DELETE statement. It follows the same
 // format as the select statement where statement.
 func (q DeleteStmt) Where(where interface{}, values ...interface{}) DeleteStmt {
@@ -27,12 +61,30 @@ func (q DeleteStmt) Where(where interface{}, values ...interface{}) DeleteStmt {
 	return q
 }
 
+// OrWhere configures the WHERE clause joining the condition with OR.
+func (q DeleteStmt) OrWhere(where interface{}, values ...interface{}) DeleteStmt {
+	q.sel = q.sel.OrWhere(where, values...)
+	return q
+}
+
+// WhereGroup adds a parenthesized group of conditions to the WHERE clause. See
+// SelectStmt.WhereGroup.
+func (q DeleteStmt) WhereGroup(fn func(SelectStmt) SelectStmt) DeleteStmt {
+	q.sel = q.sel.WhereGroup(fn)
+	return q
+}
+
 // SQL implements the SQL interface.
 func (q DeleteStmt) SQL() (string, []interface{}, error) {
-	if q.sel.err != nil {
-		return "", nil, q.sel.err
+	sql, values, err := q.unboundSQL()
+	if err != nil {
+		return "", nil, err
 	}
-	q.sel = q.sel.parseWhere()
-	sql := dialects[q.dialect].delete(q)
-	return sql, q.sel.values, q.sel.err
+	return dialects[q.dialect].rebind(sql), argValues(values), nil
+}
+
+func (q DeleteStmt) unboundSQL() (string, []interface{}, error) {
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].delete(b, q)
+	return b.result()
 }
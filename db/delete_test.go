@@ -6,7 +6,11 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestDelete_SQLWhere(t *testing.T) {
@@ -28,3 +32,101 @@ func TestDelete_SQLWhereStmt(t *testing.T) {
 			Where(Eq("name", 1)),
 	)
 }
+
+func TestDelete_SQLUsing(t *testing.T) {
+	testSQL(t,
+		"DELETE FROM users USING groups WHERE users.group_id = groups.id",
+		nil,
+		DeleteStmt{dialect: Postgres}.
+			From("users").
+			Using("groups").
+			Where("users.group_id = groups.id"),
+	)
+}
+
+func TestDelete_SQLJoin(t *testing.T) {
+	testSQL(t,
+		"DELETE FROM users USING groups WHERE (users.group_id = groups.id AND groups.kind = $1) AND (users.name = $2)",
+		[]interface{}{"a", "b"},
+		DeleteStmt{dialect: Postgres}.
+			From("users").
+			Join("groups", "users.group_id = groups.id AND groups.kind = ?", "a").
+			Where("users.name = ?", "b"),
+	)
+}
+
+func TestDelete_SQLMySQLJoin(t *testing.T) {
+	testSQL(t,
+		"DELETE users FROM users CROSS JOIN other INNER JOIN groups ON users.group_id = groups.id AND groups.kind = ? WHERE users.name = ?",
+		[]interface{}{"a", "b"},
+		DeleteStmt{dialect: MySQL}.
+			From("users").
+			Using("other").
+			Join("groups", "users.group_id = groups.id AND groups.kind = ?", "a").
+			Where("users.name = ?", "b"),
+	)
+}
+
+func TestDelete_SQLOrderLimit(t *testing.T) {
+	testSQL(t,
+		"DELETE FROM users WHERE name = ? ORDER BY id LIMIT 10",
+		[]interface{}{"test"},
+		DeleteStmt{dialect: MySQL}.
+			From("users").
+			Where("name = ?", "test").
+			OrderBy("id").
+			Limit(10),
+	)
+}
+
+func TestDelete_SQLUnsupported(t *testing.T) {
+	_, _, err := DeleteStmt{dialect: SQLite}.
+		From("users").
+		Using("groups").
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, _, err = DeleteStmt{dialect: Postgres}.
+		From("users").
+		Limit(10).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, _, err = DeleteStmt{dialect: SQLite}.
+		From("users").
+		OrderBy("id").
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, _, err = DeleteStmt{dialect: MySQL}.
+		From("users").
+		Using("groups").
+		Limit(10).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestDelete_SQLOrWhere(t *testing.T) {
+	testSQL(t,
+		"DELETE FROM users WHERE (a = ? OR b = ?)",
+		[]interface{}{1, 2},
+		Delete().From("users").Where("a = ?", 1).OrWhere("b = ?", 2),
+	)
+}
+
+func TestDelete_SQLiteOrderLimit(t *testing.T) {
+	d, err := Open("sqlite3", "file:deletelimit?mode=memory&cache=shared")
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	require.Nil(t, d.Exec(ctx, Raw("create table jobs (id int primary key)")).Err())
+
+	// The bundled SQLite is not compiled with SQLITE_ENABLE_UPDATE_DELETE_LIMIT.
+	require.Error(t, d.Exec(ctx, Raw("delete from jobs order by id limit 1")).Err())
+
+	err = d.Exec(ctx, d.Delete().From("jobs").OrderBy("id").Limit(1)).Err()
+	require.True(t, errors.Is(err, ErrUnsupported))
+	err = d.Exec(ctx, d.Update("jobs").Value("id", 1).Limit(1)).Err()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
@@ -13,24 +13,105 @@ const (
 	Generic Dialect = iota
 	Postgres
 	MySQL
+	SQLite
 )
 
-// dialects define all available dialects. Currently only differences between
-// them is rebinding the query from `?` to the desired variable placeholder.
+// dialects define all available dialects. Differences between them are
+// configured on the mapper, such as the variable placeholder used when
+// rebinding the query from `?`, the identifier quote character, how generated
+// ids are returned on insert, which clauses can be rendered and the column
+// types used in DDL statements.
 var dialects = map[Dialect]dialectMapper{
-	Generic:  genericMapper{bindType: bindQuestion},
-	Postgres: genericMapper{bindType: bindDollar},
-	MySQL:    genericMapper{bindType: bindQuestion},
+	Generic: genericMapper{
+		bindType:       bindQuestion,
+		quote:          `"`,
+		deleteUsing:    true,
+		updateFrom:     true,
+		locking:        true,
+		intersect:      true,
+		compoundParens: true,
+
+		types:            genericTypes,
+		alterActions:     true,
+		dropColumn:       true,
+		addConstraints:   true,
+		indexIfNotExists: true,
+	},
+	Postgres: genericMapper{
+		bindType:       bindDollar,
+		quote:          `"`,
+		returning:      true,
+		ilike:          true,
+		deleteUsing:    true,
+		updateFrom:     true,
+		distinctOn:     true,
+		locking:        true,
+		intersect:      true,
+		compoundParens: true,
+
+		types:            postgresTypes,
+		alterActions:     true,
+		dropColumn:       true,
+		addConstraints:   true,
+		indexIfNotExists: true,
+	},
+	MySQL: genericMapper{
+		bindType:       bindQuestion,
+		lastID:         lastIDFirst,
+		quote:          "`",
+		backslash:      true,
+		modifyJoin:     true,
+		orderLimit:     true,
+		insertWith:     true,
+		locking:        true,
+		compoundParens: true,
+
+		types:          mysqlTypes,
+		alterActions:   true,
+		dropColumn:     true,
+		addConstraints: true,
+	},
+	SQLite: genericMapper{
+		bindType:  bindQuestion,
+		lastID:    lastIDLast,
+		quote:     `"`,
+		intersect: true,
+
+		types:            sqliteTypes,
+		indexIfNotExists: true,
+	},
 }
 
 // dialectMapper provides a mapper for different dialects.
 type dialectMapper interface {
-	query(q SelectStmt) string
-	insert(i InsertStmt) string
-	update(q UpdateStmt) string
-	delete(q DeleteStmt) string
+	query(b *builder, q SelectStmt)
+	insert(b *builder, i InsertStmt)
+	update(b *builder, q UpdateStmt)
+	delete(b *builder, q DeleteStmt)
+	createTable(b *builder, q CreateTableStmt)
+	createIndex(b *builder, q CreateIndexStmt)
+	alterTable(b *builder, q AlterTableStmt)
+	dropTable(b *builder, q DropTableStmt)
+
+	// identifier quotes a column or table name for the dialect.
+	identifier(name string) string
+
+	// rebind replaces the '?' placeholders in a built query with the
+	// dialect's bind type.
+	rebind(query string) string
 
 	beginSavepoint(name string) string
 	releaseSavepoint(name string) string
 	rollbackSavepoint(name string) string
+
+	// supportsReturning reports whether INSERT ... RETURNING can be used.
+	supportsReturning() bool
+	// lastInsertIDs computes the ids generated by a multi row insert given the
+	// value from LastInsertId.
+	lastInsertIDs(lastID int64, rows int) ([]int64, error)
+	// supportsILike reports whether ILIKE can be used.
+	supportsILike() bool
+	// backslashEscapes reports whether a backslash escapes the next character
+	// in quoted strings.
+	backslashEscapes() bool
 }
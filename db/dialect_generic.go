@@ -6,12 +6,71 @@
 package db
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 )
 
 type genericMapper struct {
-	bindType int
+	bindType    int    // Placeholder style used when rebinding.
+	returning   bool   // INSERT ... RETURNING for returning columns.
+	lastID      int    // Position of LastInsertId in multi row inserts.
+	modifyJoin  bool   // MySQL style joins in UPDATE and DELETE.
+	deleteUsing bool   // DELETE ... USING for joins in DELETE.
+	updateFrom  bool   // UPDATE ... FROM for joins in UPDATE.
+	orderLimit  bool   // ORDER BY and LIMIT in UPDATE and DELETE.
+	insertWith  bool   // WITH is written after INSERT INTO in INSERT ... SELECT.
+	ilike       bool   // ILIKE for case insensitive LIKE.
+	quote       string // Identifier quote character.
+	backslash   bool   // Backslash escapes in quoted strings.
+
+	distinctOn     bool // SELECT DISTINCT ON (...).
+	locking        bool // Row locking clauses such as FOR UPDATE.
+	intersect      bool // INTERSECT and EXCEPT set operations.
+	compoundParens bool // Parenthesized selects in set operations.
+
+	types            map[ColumnType]string // Names of the portable column types.
+	alterActions     bool                  // Multiple actions in one ALTER TABLE.
+	dropColumn       bool                  // ALTER TABLE ... DROP COLUMN.
+	addConstraints   bool                  // UNIQUE and PRIMARY KEY in ADD COLUMN.
+	indexIfNotExists bool                  // CREATE INDEX IF NOT EXISTS.
+}
+
+// unsupported returns an error for a clause that the dialect cannot render.
+func unsupported(clause string) error {
+	return fmt.Errorf("%w: %s", ErrUnsupported, clause)
+}
+
+// Positions of the value returned by LastInsertId in a multi row insert.
+const (
+	lastIDUnknown int = iota
+	lastIDFirst
+	lastIDLast
+)
+
+func (m genericMapper) supportsReturning() bool {
+	return m.returning
+}
+
+func (m genericMapper) supportsILike() bool {
+	return m.ilike
+}
+
+func (m genericMapper) lastInsertIDs(lastID int64, rows int) ([]int64, error) {
+	first := lastID
+	switch {
+	case rows == 1:
+	case m.lastID == lastIDFirst:
+	case m.lastID == lastIDLast:
+		first = lastID - int64(rows) + 1
+	default:
+		return nil, ErrReturningUnsupported
+	}
+	ids := make([]int64, rows)
+	for i := range ids {
+		ids[i] = first + int64(i)
+	}
+	return ids, nil
 }
 
 func (m genericMapper) beginSavepoint(name string) string {
@@ -26,92 +85,419 @@ func (m genericMapper) rollbackSavepoint(name string) string {
 	return "ROLLBACK TO SAVEPOINT " + name
 }
 
-func (m genericMapper) query(q SelectStmt) string {
-	sql := strings.Builder{}
-	sql.WriteString("SELECT ")
-	sql.WriteString(strings.Join(q.columns, ","))
-	sql.WriteString(" FROM ")
-	sql.WriteString(q.table)
+func (m genericMapper) rebind(query string) string {
+	return rebind(m.bindType, query, m.backslash)
+}
+
+func (m genericMapper) backslashEscapes() bool {
+	return m.backslash
+}
 
-	for _, join := range q.join {
-		sql.WriteString(" ")
-		sql.WriteString(join[0])
-		sql.WriteString(" JOIN ")
-		sql.WriteString(join[1])
-		sql.WriteString(" ON ")
-		sql.WriteString(join[2])
+func (m genericMapper) query(b *builder, q SelectStmt) {
+	q = q.applyKeyset()
+	if q.lock == "" && (q.lockOf != nil || q.lockWait != "") {
+		b.fail(ErrStatementInvalid)
+		return
 	}
-	if q.where != "" {
-		sql.WriteString(" WHERE ")
-		sql.WriteString(q.where)
+	if q.lock != "" && q.compound != nil {
+		b.fail(unsupported("FOR " + q.lock + " with set operations"))
+		return
 	}
-	if q.groupBy != nil {
-		sql.WriteString(" GROUP BY ")
-		sql.WriteString(strings.Join(q.groupBy, ", "))
+	m.with(b, q.with)
+	closes := m.compoundGroups(q.compound)
+	for _, close := range closes {
+		if close {
+			b.WriteString("(")
+		}
+	}
+	m.selectCore(b, q)
+	for i, c := range q.compound {
+		if closes[i] {
+			b.WriteString(")")
+		}
+		m.compound(b, c)
 	}
 	if q.orderBy != nil {
-		sql.WriteString(" ORDER BY ")
-		sql.WriteString(strings.Join(q.orderBy, ", "))
+		b.WriteString(" ORDER BY ")
+		m.idents(b, q.orderBy, ", ")
 	}
 	if q.limit != "" {
-		sql.WriteString(" LIMIT ")
-		sql.WriteString(q.limit)
+		b.WriteString(" LIMIT ")
+		b.WriteString(q.limit)
 	}
 	if q.offset != "" {
-		sql.WriteString(" OFFSET ")
-		sql.WriteString(q.offset)
+		b.WriteString(" OFFSET ")
+		b.WriteString(q.offset)
+	}
+	if q.lock != "" && m.locking {
+		b.WriteString(" FOR ")
+		b.WriteString(q.lock)
+		if q.lockOf != nil {
+			b.WriteString(" OF ")
+			m.idents(b, q.lockOf, ", ")
+		}
+		if q.lockWait != "" {
+			b.WriteString(" ")
+			b.WriteString(q.lockWait)
+		}
+	}
+}
+
+// selectCore writes a select without the clauses that apply to the result of
+// a compound select, which are WITH, ORDER BY, LIMIT and OFFSET.
+func (m genericMapper) selectCore(b *builder, q SelectStmt) {
+	if q.err != nil {
+		b.fail(q.err)
+		return
+	}
+	q = q.renderWhere(m, b.quote)
+	if q.err != nil {
+		b.fail(q.err)
+		return
+	}
+
+	b.WriteString("SELECT ")
+	if q.distinctOn != nil {
+		if !m.distinctOn {
+			b.fail(unsupported("DISTINCT ON"))
+			return
+		}
+		b.WriteString("DISTINCT ON (")
+		m.idents(b, q.distinctOn, ", ")
+		b.WriteString(") ")
+	} else if q.distinct {
+		b.WriteString("DISTINCT ")
+	}
+	m.idents(b, q.columns, ",")
+	if q.table == "" && q.tableSel == nil {
+		// A select without a FROM clause cannot join other tables.
+		if q.join != nil {
+			b.fail(ErrStatementInvalid)
+			return
+		}
+	} else {
+		b.WriteString(" FROM ")
+		m.table(b, q.table, q.tableSel)
+	}
+
+	m.joins(b, q.join)
+	if q.where != "" {
+		b.bind(" WHERE "+q.where, q.values...)
+	}
+	if q.groupBy != nil {
+		b.WriteString(" GROUP BY ")
+		m.idents(b, q.groupBy, ", ")
+	}
+	having, values, err := q.having.render(m, b.quote)
+	if err != nil {
+		b.fail(err)
+		return
+	}
+	if having != "" {
+		b.bind(" HAVING "+having, values...)
+	}
+	for i, w := range q.windows {
+		if i == 0 {
+			b.WriteString(" WINDOW ")
+		} else {
+			b.WriteString(", ")
+		}
+		m.ident(b, w.name)
+		b.WriteString(" AS (")
+		b.WriteString(w.window.String())
+		b.WriteString(")")
+	}
+}
+
+// compoundGroups reports which set operations need the selects before them
+// grouped in parentheses. Set operations are applied in the order they are
+// added, but INTERSECT binds tighter than UNION and EXCEPT in standard SQL, so
+// an INTERSECT following a UNION or EXCEPT would otherwise apply only to the
+// select before it. SQLite evaluates set operations left to right and does
+// not accept the parentheses.
+func (m genericMapper) compoundGroups(compounds []compound) []bool {
+	closes := make([]bool, len(compounds))
+	if !m.compoundParens {
+		return closes
+	}
+	var loose bool
+	for i, c := range compounds {
+		switch {
+		case c.op == "INTERSECT" && loose:
+			closes[i], loose = true, false
+		case c.op != "INTERSECT":
+			loose = true
+		}
+	}
+	return closes
+}
+
+// compound writes a select combined using a set operation. Selects which have
+// their own WITH, ORDER BY, LIMIT or set operations are wrapped in parentheses
+// if the dialect allows it.
+func (m genericMapper) compound(b *builder, c compound) {
+	if !m.intersect && (c.op == "INTERSECT" || c.op == "EXCEPT") {
+		b.fail(unsupported(c.op))
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(c.op)
+	b.WriteString(" ")
+
+	sel := c.sel
+	if sel.with == nil && sel.compound == nil && sel.orderBy == nil &&
+		sel.limit == "" && sel.offset == "" {
+		m.selectCore(b, sel)
+		return
+	}
+	if !m.compoundParens {
+		b.fail(unsupported(c.op + " with a parenthesized select"))
+		return
 	}
-	return rebind(m.bindType, sql.String())
+	b.WriteString("(")
+	m.query(b, sel)
+	b.WriteString(")")
 }
 
-func (m genericMapper) delete(q DeleteStmt) string {
-	sql := strings.Builder{}
-	sql.WriteString("DELETE FROM ")
-	sql.WriteString(q.sel.table)
-	sql.WriteString(" ")
-	if q.sel.where != "" {
-		sql.WriteString("WHERE ")
-		sql.WriteString(q.sel.where)
-		sql.WriteString(" ")
+func (m genericMapper) delete(b *builder, q DeleteStmt) {
+	sel := q.sel.renderWhere(m, b.quote)
+	if sel.err != nil {
+		b.fail(sel.err)
+		return
 	}
-	return rebind(m.bindType, sql.String())
+	multi := q.using != nil || sel.join != nil
+	m.with(b, sel.with)
+
+	if !multi {
+		b.WriteString("DELETE FROM ")
+		m.ident(b, sel.table)
+		if sel.where != "" {
+			b.bind(" WHERE "+sel.where, sel.values...)
+		}
+		m.writeOrderLimit(b, "DELETE", sel, multi)
+		return
+	}
+
+	switch {
+	case m.modifyJoin:
+		b.WriteString("DELETE ")
+		m.ident(b, sel.table)
+		b.WriteString(" FROM ")
+		m.ident(b, sel.table)
+		for _, table := range q.using {
+			b.WriteString(" CROSS JOIN ")
+			m.ident(b, table)
+		}
+		m.joins(b, sel.join)
+		if sel.where != "" {
+			b.bind(" WHERE "+sel.where, sel.values...)
+		}
+	case m.deleteUsing:
+		tables := append([]string{}, q.using...)
+		for _, join := range sel.join {
+			tables = append(tables, join.table)
+		}
+		b.WriteString("DELETE FROM ")
+		m.ident(b, sel.table)
+		b.WriteString(" USING ")
+		m.idents(b, tables, ", ")
+		m.joinWhere(b, sel)
+	default:
+		b.fail(unsupported("DELETE with USING or JOIN"))
+		return
+	}
+	m.writeOrderLimit(b, "DELETE", sel, multi)
 }
 
-func (m genericMapper) insert(q InsertStmt) string {
-	sql := strings.Builder{}
-	sql.WriteString("INSERT INTO ")
-	sql.WriteString(q.table)
-	sql.WriteString(" (")
-	sql.WriteString(strings.Join(q.columns, ", "))
-	sql.WriteString(") VALUES ")
-	for i, row := range q.rows {
-		sql.WriteString(questions(len(row)))
-		if i != len(q.rows)-1 {
-			sql.WriteString(", ")
+func (m genericMapper) insert(b *builder, q InsertStmt) {
+	if m.insertWith && q.with != nil {
+		if q.sel == nil {
+			b.fail(unsupported("INSERT with WITH and VALUES"))
+			return
 		}
+		// The WITH clause is written as part of the SELECT.
+		sel := *q.sel
+		sel.with = append(q.with[:len(q.with):len(q.with)], sel.with...)
+		q.sel = &sel
+		q.with = nil
+	}
+
+	m.with(b, q.with)
+	b.WriteString("INSERT INTO ")
+	m.ident(b, q.table)
+	if q.columns != nil {
+		b.WriteString(" (")
+		m.idents(b, q.columns, ", ")
+		b.WriteString(")")
+	}
+	if q.sel != nil {
+		b.WriteString(" ")
+		m.query(b, *q.sel)
+	} else {
+		b.WriteString(" VALUES ")
+		for i, row := range q.rows {
+			b.bind(questions(len(row)), row...)
+			if i != len(q.rows)-1 {
+				b.WriteString(", ")
+			}
+		}
+	}
+	if q.returning != nil && m.returning {
+		b.WriteString(" RETURNING ")
+		m.idents(b, q.returning, ", ")
 	}
-	return rebind(m.bindType, sql.String())
 }
 
-func (m genericMapper) update(q UpdateStmt) string {
-	sql := strings.Builder{}
-	sql.WriteString("UPDATE ")
-	sql.WriteString(q.table)
-	sql.WriteString(" SET ")
+func (m genericMapper) update(b *builder, q UpdateStmt) {
+	sel := q.sel.renderWhere(m, b.quote)
+	if sel.err != nil {
+		b.fail(sel.err)
+		return
+	}
+	multi := sel.table != "" || sel.join != nil
+	if multi && !m.modifyJoin && !m.updateFrom {
+		b.fail(unsupported("UPDATE with FROM or joins"))
+		return
+	}
+
+	m.with(b, sel.with)
+	b.WriteString("UPDATE ")
+	m.ident(b, q.table)
+	if m.modifyJoin {
+		if sel.table != "" {
+			b.WriteString(" CROSS JOIN ")
+			m.ident(b, sel.table)
+		}
+		m.joins(b, sel.join)
+	}
+	b.WriteString(" SET ")
 	for i := range q.columns {
-		sql.WriteString(q.columns[i])
-		sql.WriteString("=?")
+		m.ident(b, q.columns[i])
+		b.WriteString("=")
+		b.sql(valueSQL(q.values[i]))
 		if i == len(q.columns)-1 {
-			sql.WriteString(" ")
+			b.WriteString(" ")
+		} else {
+			b.WriteString(", ")
+		}
+	}
+	if m.modifyJoin || !multi {
+		if sel.where != "" {
+			b.bind("WHERE "+sel.where, sel.values...)
+		}
+	} else {
+		// Without join support the tables are listed in FROM and the join
+		// conditions are moved into the WHERE clause.
+		tables := make([]string, 0, len(sel.join)+1)
+		if sel.table != "" {
+			tables = append(tables, sel.table)
+		}
+		for _, join := range sel.join {
+			tables = append(tables, join.table)
+		}
+		b.WriteString("FROM ")
+		m.idents(b, tables, ", ")
+		m.joinWhere(b, sel)
+	}
+	m.writeOrderLimit(b, "UPDATE", sel, multi)
+}
+
+// with writes a WITH clause for common table expressions.
+func (m genericMapper) with(b *builder, ctes []cte) {
+	if ctes == nil {
+		return
+	}
+	b.WriteString("WITH ")
+	for _, c := range ctes {
+		if c.recursive {
+			b.WriteString("RECURSIVE ")
+			break
+		}
+	}
+	for i, c := range ctes {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		m.ident(b, c.name)
+		if c.columns != nil {
+			b.WriteString(" (")
+			m.idents(b, c.columns, ", ")
+			b.WriteString(")")
+		}
+		b.WriteString(" AS (")
+		b.sql(c.sql)
+		b.WriteString(")")
+	}
+	b.WriteString(" ")
+}
+
+// joins writes JOIN clauses.
+func (m genericMapper) joins(b *builder, joins []joinClause) {
+	for _, join := range joins {
+		b.WriteString(" ")
+		b.WriteString(join.kind)
+		b.WriteString(" JOIN ")
+		m.table(b, join.table, join.sel)
+		b.bind(" ON "+join.on, join.values...)
+	}
+}
+
+// table writes a table name, or a derived table with the name as its alias.
+func (m genericMapper) table(b *builder, table string, sel *SelectStmt) {
+	if sel == nil {
+		m.ident(b, table)
+		return
+	}
+	b.WriteString("(")
+	m.query(b, *sel)
+	b.WriteString(") AS ")
+	m.ident(b, table)
+}
+
+// joinWhere writes a WHERE clause which combines the join conditions with the
+// where clause of the statement.
+func (m genericMapper) joinWhere(b *builder, sel SelectStmt) {
+	if sel.join == nil {
+		if sel.where != "" {
+			b.bind(" WHERE "+sel.where, sel.values...)
+		}
+		return
+	}
+	for i, join := range sel.join {
+		if i == 0 {
+			b.WriteString(" WHERE ")
 		} else {
-			sql.WriteString(", ")
+			b.WriteString(" AND ")
 		}
+		b.bind("("+join.on+")", join.values...)
+	}
+	if sel.where != "" {
+		b.bind(" AND ("+sel.where+")", sel.values...)
+	}
+}
+
+// writeOrderLimit writes the ORDER BY and LIMIT clauses for an UPDATE or DELETE.
+// These are only supported for single table statements.
+func (m genericMapper) writeOrderLimit(b *builder, stmt string, sel SelectStmt, multi bool) {
+	if sel.orderBy == nil && sel.limit == "" {
+		return
+	}
+	if !m.orderLimit {
+		b.fail(unsupported(stmt + " with ORDER BY or LIMIT"))
+		return
+	}
+	if multi {
+		b.fail(unsupported("multiple table " + stmt + " with ORDER BY or LIMIT"))
+		return
 	}
-	if q.sel.where != "" {
-		sql.WriteString("WHERE ")
-		sql.WriteString(q.sel.where)
+	if sel.orderBy != nil {
+		b.WriteString(" ORDER BY ")
+		m.idents(b, sel.orderBy, ", ")
+	}
+	if sel.limit != "" {
+		b.WriteString(" LIMIT ")
+		b.WriteString(sel.limit)
 	}
-	return rebind(m.bindType, sql.String())
 }
 
 func questions(count int) string {
@@ -135,32 +521,33 @@ const (
 	bindQuestion
 )
 
-func rebind(bindType int, query string) string {
-	switch bindType {
-	case bindQuestion, bindUnknown:
+// rebind replaces '?' placeholders with the bind type and '??' with a literal
+// '?'. Placeholders in quoted strings, identifiers and comments are ignored.
+func rebind(bindType int, query string, backslash bool) string {
+	if !strings.Contains(query, "?") {
 		return query
 	}
 
-	// Add space enough for 10 params before we have to allocate
-	rqb := make([]byte, 0, len(query)+10)
-
-	var i, j int
-
-	for i = strings.Index(query, "?"); i != -1; i = strings.Index(query, "?") {
-		rqb = append(rqb, query[:i]...)
-
-		switch bindType {
-		case bindDollar:
-			rqb = append(rqb, '$')
-		case bindNamed:
-			rqb = append(rqb, ':', 'a', 'r', 'g')
+	var out strings.Builder
+	out.Grow(len(query) + 10)
+	var j int
+	for _, t := range scanSQL(query, backslash) {
+		switch t.kind {
+		case tokenPlaceholder:
+			j++
+			switch bindType {
+			case bindDollar:
+				out.WriteString("$" + strconv.Itoa(j))
+			case bindNamed:
+				out.WriteString(":arg" + strconv.Itoa(j))
+			default:
+				out.WriteString("?")
+			}
+		case tokenEscaped:
+			out.WriteString("?")
+		default:
+			out.WriteString(t.text)
 		}
-
-		j++
-		rqb = strconv.AppendInt(rqb, int64(j), 10)
-
-		query = query[i+1:]
 	}
-
-	return string(append(rqb, query...))
+	return out.String()
 }
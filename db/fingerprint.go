@@ -0,0 +1,173 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"encoding/hex"
+	"hash/fnv"
+	"strings"
+)
+
+// Fingerprint normalises a statement so that queries with the same shape can
+// be grouped, for example in metrics. It returns a stable hash of the
+// normalised text along with the text itself.
+//
+// Literals and placeholders of any bind style are replaced with '?', comments
+// are removed and whitespace is standardised. Lists of placeholders such as
+// "IN (?, ?, ?)" and multiple rows of VALUES are collapsed into "(?+)". The
+// dialect decides whether backslashes escape quotes in strings, as on MySQL.
+func Fingerprint(dialect Dialect, s SQL) (hash, text string, err error) {
+	sql, _, err := unbound(s)
+	if err != nil {
+		return "", "", err
+	}
+	text = joinTokens(collapseLists(fingerprintTokens(sql, dialects[dialect].backslashEscapes())))
+	h := fnv.New64a()
+	h.Write([]byte(text))
+	return hex.EncodeToString(h.Sum(nil)), text, nil
+}
+
+type lexToken struct {
+	text  string
+	space bool // Whitespace came before the token.
+}
+
+// fingerprintTokens splits the tokens from scanSQL into words with literals
+// and placeholders replaced by '?' and comments removed. See scanSQL for
+// backslash.
+func fingerprintTokens(sql string, backslash bool) []lexToken {
+	var l lexer
+	for _, t := range scanSQL(sql, backslash) {
+		switch t.kind {
+		case tokenPlaceholder, tokenNumbered, tokenString:
+			l.emit("?")
+		case tokenEscaped, tokenQuoted:
+			l.emit(t.text)
+		case tokenComment:
+			l.space = true
+		default:
+			l.words(t.text)
+		}
+	}
+	return l.tokens
+}
+
+type lexer struct {
+	tokens []lexToken
+	space  bool
+}
+
+func (l *lexer) emit(text string) {
+	l.tokens = append(l.tokens, lexToken{text: text, space: l.space})
+	l.space = false
+}
+
+// words splits text into words, numbers and operators, with numbers replaced
+// by '?'.
+func (l *lexer) words(text string) {
+	for i := 0; i < len(text); {
+		c := text[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			l.space = true
+			i++
+		case c >= '0' && c <= '9' ||
+			c == '.' && i+1 < len(text) && text[i+1] >= '0' && text[i+1] <= '9':
+			end := i + 1
+			for end < len(text) && isNumberByte(text[end-1], text[end]) {
+				end++
+			}
+			l.emit("?")
+			i = end
+		case isIdentByte(c):
+			end := i + 1
+			for end < len(text) && isIdentByte(text[end]) {
+				end++
+			}
+			l.emit(text[i:end])
+			i = end
+		case c == '(' || c == ')' || c == ',' || c == ';':
+			l.emit(text[i : i+1])
+			i++
+		default:
+			end := i + 1
+			for end < len(text) && strings.IndexByte("=<>!|&+-*/%^~:.@#?", text[end]) >= 0 {
+				end++
+			}
+			l.emit(text[i:end])
+			i = end
+		}
+	}
+}
+
+func isNumberByte(prev, c byte) bool {
+	switch {
+	case c >= '0' && c <= '9', c == '.', c == 'e', c == 'E':
+		return true
+	case c == '+' || c == '-':
+		return prev == 'e' || prev == 'E'
+	}
+	return false
+}
+
+// collapseLists replaces lists of placeholders with "(?+)" and repeated lists
+// with a single list.
+func collapseLists(tokens []lexToken) []lexToken {
+	out := make([]lexToken, 0, len(tokens))
+	for i := 0; i < len(tokens); i++ {
+		if tokens[i].text == "(" {
+			if end := placeholderList(tokens, i); end > 0 {
+				list := lexToken{text: "(?+)", space: tokens[i].space}
+				n := len(out)
+				if n >= 2 && out[n-1].text == "," && out[n-2].text == "(?+)" {
+					out = out[:n-1]
+				} else {
+					out = append(out, list)
+				}
+				i = end
+				continue
+			}
+		}
+		out = append(out, tokens[i])
+	}
+	return out
+}
+
+// placeholderList returns the index of the closing parenthesis if the tokens
+// starting at i are a parenthesized list of placeholders, otherwise 0.
+func placeholderList(tokens []lexToken, i int) int {
+	for j := i + 1; j+1 < len(tokens); j += 2 {
+		if tokens[j].text != "?" {
+			return 0
+		}
+		switch tokens[j+1].text {
+		case ")":
+			return j + 1
+		case ",":
+		default:
+			return 0
+		}
+	}
+	return 0
+}
+
+// joinTokens writes tokens separated by single spaces. Spaces are removed
+// inside parentheses and before commas, and always written after commas.
+func joinTokens(tokens []lexToken) string {
+	var out strings.Builder
+	for i, t := range tokens {
+		if i > 0 {
+			prev := tokens[i-1].text
+			switch {
+			case prev == "(" || t.text == ")" || t.text == "," || t.text == ";":
+			case prev == "," || t.space:
+				out.WriteByte(' ')
+			}
+		}
+		out.WriteString(t.text)
+	}
+	return out.String()
+}
@@ -0,0 +1,55 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFingerprint(t *testing.T) {
+	hash1, text, err := Fingerprint(Generic, Select("*").From("users").Where(In("id", []int{1, 2, 3})).Where("name = ?", "a"))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM users WHERE ((id IN (?+)) AND name = ?)", text)
+
+	hash2, _, err := Fingerprint(Postgres, SelectStmt{dialect: Postgres}.Select("*").From("users").Where(In("id", []int{1})).Where("name = ?", "b"))
+	require.NoError(t, err)
+	require.Equal(t, hash1, hash2)
+
+	hash3, _, err := Fingerprint(Generic, Select("*").From("users").Where(In("id", []int{1})))
+	require.NoError(t, err)
+	require.NotEqual(t, hash1, hash3)
+}
+
+func TestFingerprint_Normalise(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"SELECT  *\n  FROM users -- comment\n WHERE id = 10", "SELECT * FROM users WHERE id = ?"},
+		{"SELECT * FROM users WHERE name = 'o''neil' /* c */ AND x = 1.5e-3", "SELECT * FROM users WHERE name = ? AND x = ?"},
+		{"SELECT * FROM users WHERE id IN ( $1,$2 , $3 )", "SELECT * FROM users WHERE id IN (?+)"},
+		{"INSERT INTO users (id, name) VALUES (?, ?), (?, ?), (?, ?)", "INSERT INTO users (id, name) VALUES (?+)"},
+		{`SELECT "col1", data ?? 'k', data ?| $1 FROM t1`, `SELECT "col1", data ?? ?, data ?| ? FROM t1`},
+		{"SELECT $$ a; b $$, count(*) FROM t WHERE a<=2", "SELECT ?, count(*) FROM t WHERE a<=?"},
+		{"SELECT E'it\\'s ?', `a?` FROM t WHERE b IN (?,?)", "SELECT ?, `a?` FROM t WHERE b IN (?+)"},
+	} {
+		_, text, err := Fingerprint(Postgres, Raw(tc.in))
+		require.NoError(t, err)
+		require.Equal(t, tc.out, text, tc.in)
+	}
+}
+
+func TestFingerprint_MySQL(t *testing.T) {
+	_, text, err := Fingerprint(MySQL, Raw(`SELECT * FROM t WHERE x = 'it\'s' AND y = ?`))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM t WHERE x = ? AND y = ?", text)
+
+	// Without backslash escapes the quote ends the string.
+	_, text, err = Fingerprint(Postgres, Raw(`SELECT * FROM t WHERE x = 'a\' AND y = ?`))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM t WHERE x = ? AND y = ?", text)
+}
@@ -6,6 +6,9 @@
 package db
 
 import (
+	"database/sql"
+	"reflect"
+
 	"github.com/colinjfw/sqlkit/encoding"
 )
 
@@ -14,12 +17,17 @@ func Insert() InsertStmt { return InsertStmt{} }
 
 // InsertStmt represents an INSERT in SQL.
 type InsertStmt struct {
-	dialect Dialect
-	table   string
-	columns []string
-	rows    [][]interface{}
-	err     error
-	encoder encoding.Encoder
+	dialect   Dialect
+	quote     bool
+	with      []cte
+	table     string
+	columns   []string
+	rows      [][]interface{}
+	records   []interface{}
+	sel       *SelectStmt
+	returning []string
+	err       error
+	encoder   encoding.Encoder
 }
 
 // Into configures the table name.
@@ -30,13 +38,14 @@ func (i InsertStmt) Into(table string) InsertStmt {
 
 // Columns configures the columns.
 func (i InsertStmt) Columns(cols ...string) InsertStmt {
-	i.columns = cols
+	i.columns = append([]string(nil), cols...)
 	return i
 }
 
 // Values configures a single row of values.
 func (i InsertStmt) Values(vals ...interface{}) InsertStmt {
-	i.rows = append(i.rows, vals)
+	i.rows = append(i.rows[:len(i.rows):len(i.rows)], append([]interface{}(nil), vals...))
+	i.records = append(i.records[:len(i.records):len(i.records)], nil)
 	return i
 }
 
@@ -54,16 +63,60 @@ func (i InsertStmt) Record(obj interface{}, fields ...string) InsertStmt {
 		i.err = err
 		return i
 	}
-	return i.Row(cols, vals)
+	return i.row(cols, vals, obj)
+}
+
+// Records will call Record for every element in a slice of structs or struct
+// pointers.
+func (i InsertStmt) Records(objs interface{}, fields ...string) InsertStmt {
+	v := reflect.Indirect(reflect.ValueOf(objs))
+	if v.Kind() != reflect.Slice {
+		i.err = ErrStatementInvalid
+		return i
+	}
+	for n := 0; n < v.Len(); n++ {
+		obj := v.Index(n)
+		if obj.Kind() != reflect.Ptr {
+			obj = obj.Addr()
+		}
+		i = i.Record(obj.Interface(), fields...)
+	}
+	return i
+}
+
+// Returning configures columns that are written back into the records passed
+// to Record or Records when the statement is run with DB.Exec. Records must be
+// pointers for this to work.
+//
+// Dialects supporting INSERT ... RETURNING will select the columns back from
+// the inserted rows, so any generated or default value can be returned. Other
+// dialects can only return a single auto increment column, the value is
+// computed using LastInsertId assuming that the ids of a multi row insert are
+// consecutive.
+func (i InsertStmt) Returning(cols ...string) InsertStmt {
+	i.returning = append([]string(nil), cols...)
+	return i
+}
+
+// FromSelect configures the rows to be inserted from a SELECT statement. This
+// renders as INSERT INTO table (columns) SELECT ... and cannot be combined with
+// rows configured using Values, Row or Record.
+func (i InsertStmt) FromSelect(sel SelectStmt) InsertStmt {
+	i.sel = &sel
+	return i
 }
 
 // Row configures a single row into the insert statement. If the columns don't
 // match previous insert statements then an error is forwarded.
 func (i InsertStmt) Row(cols []string, vals []interface{}) InsertStmt {
+	return i.row(cols, vals, nil)
+}
+
+func (i InsertStmt) row(cols []string, vals []interface{}, obj interface{}) InsertStmt {
 	// Only write if nil to allow multiple record calls. Only the first will
 	// configure the columns.
 	if i.columns == nil {
-		i.columns = cols
+		i.columns = append([]string(nil), cols...)
 	}
 	// Validate that columns are the same for this record.
 	if len(i.columns) != len(cols) {
@@ -82,19 +135,96 @@ func (i InsertStmt) Row(cols []string, vals []interface{}) InsertStmt {
 			return i
 		}
 	}
-	i.rows = append(i.rows, vals)
+	i.rows = append(i.rows[:len(i.rows):len(i.rows)], append([]interface{}(nil), vals...))
+	i.records = append(i.records[:len(i.records):len(i.records)], obj)
 	return i
 }
 
 // SQL implements the SQL interface.
 func (i InsertStmt) SQL() (string, []interface{}, error) {
-	values := []interface{}{}
+	sql, values, err := i.unboundSQL()
+	if err != nil {
+		return "", nil, err
+	}
+	return dialects[i.dialect].rebind(sql), argValues(values), nil
+}
+
+func (i InsertStmt) unboundSQL() (string, []interface{}, error) {
+	if i.err != nil {
+		return "", nil, i.err
+	}
+	if i.sel != nil && i.rows != nil || i.sel == nil && len(i.rows) == 0 {
+		return "", nil, ErrStatementInvalid
+	}
 	for _, row := range i.rows {
 		if len(i.columns) != len(row) {
 			return "", nil, ErrStatementInvalid
 		}
-		values = append(values, row...)
 	}
-	sql := dialects[i.dialect].insert(i)
-	return sql, values, i.err
+	b := &builder{quote: i.quote}
+	dialects[i.dialect].insert(b, i)
+	return b.result()
+}
+
+// checkReturning validates that the returning columns can be written back into
+// the records before the statement is executed.
+func (i InsertStmt) checkReturning() error {
+	if i.err != nil {
+		return i.err
+	}
+	for _, obj := range i.records {
+		if obj == nil {
+			continue
+		}
+		v := reflect.ValueOf(obj)
+		if v.Kind() != reflect.Ptr || v.IsNil() {
+			return encoding.ErrRequiresPtr
+		}
+	}
+	m := dialects[i.dialect]
+	if m.supportsReturning() {
+		return nil
+	}
+	if len(i.returning) != 1 {
+		return ErrReturningUnsupported
+	}
+	_, err := m.lastInsertIDs(0, len(i.rows))
+	return err
+}
+
+// scanReturning decodes the rows from an INSERT ... RETURNING into the records.
+func (i InsertStmt) scanReturning(rows *sql.Rows) (int64, error) {
+	var count int64
+	for _, obj := range i.records {
+		if obj == nil {
+			if !rows.Next() {
+				return count, encoding.ErrNoRows
+			}
+		} else if err := i.encoder.Decode(obj, rows); err != nil {
+			return count, err
+		}
+		count++
+	}
+	// Inserts from a select have no records to write into.
+	for rows.Next() {
+		count++
+	}
+	return count, rows.Err()
+}
+
+// assignLastID writes the ids computed from LastInsertId into the records.
+func (i InsertStmt) assignLastID(lastID int64) error {
+	ids, err := dialects[i.dialect].lastInsertIDs(lastID, len(i.rows))
+	if err != nil {
+		return err
+	}
+	for n, obj := range i.records {
+		if obj == nil {
+			continue
+		}
+		if err := i.encoder.Assign(obj, i.returning[0], ids[n]); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -6,6 +6,7 @@
 package db
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -133,3 +134,115 @@ func TestInsert_Postgres(t *testing.T) {
 			Values("1", "2"),
 	)
 }
+
+func TestInsert_PostgresReturning(t *testing.T) {
+	testSQL(t,
+		"INSERT INTO users (c1) VALUES ($1) RETURNING id, created_at",
+		[]interface{}{"1"},
+		InsertStmt{dialect: Postgres}.
+			Into("users").
+			Value("c1", "1").
+			Returning("id", "created_at"),
+	)
+}
+
+func TestInsert_MySQLReturning(t *testing.T) {
+	testSQL(t,
+		"INSERT INTO users (c1) VALUES (?)",
+		[]interface{}{"1"},
+		InsertStmt{dialect: MySQL}.
+			Into("users").
+			Value("c1", "1").
+			Returning("id"),
+	)
+}
+
+func TestInsert_SQLRecords(t *testing.T) {
+	type user struct {
+		ID   int    `sql:"id"`
+		Name string `sql:"name"`
+	}
+	testSQL(t,
+		"INSERT INTO users (id, name) VALUES (?, ?), (?, ?)",
+		[]interface{}{1, "a", 2, "b"},
+		Insert().
+			Into("users").
+			Records([]user{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}),
+	)
+}
+
+func TestInsert_EmptyRecords(t *testing.T) {
+	type user struct {
+		ID int `sql:"id"`
+	}
+	_, _, err := Insert().Into("users").Records([]user{}).SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+	_, _, err = Insert().Into("users").SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+}
+
+func TestInsert_ScanReturningFromSelect(t *testing.T) {
+	d, err := Open("sqlite3", "file:insertreturning?mode=memory&cache=shared")
+	require.Nil(t, err)
+	defer d.Close()
+
+	r := d.Query(context.Background(), Raw("SELECT 1 AS id UNION ALL SELECT 2"))
+	require.Nil(t, r.Err())
+	defer r.Rows.Close()
+
+	// Rows inserted from a select are counted without records to decode into.
+	i := Insert().Into("archive").Columns("id").FromSelect(Select("id").From("users")).Returning("id")
+	affected, err := i.scanReturning(r.Rows)
+	require.Nil(t, err)
+	require.Equal(t, int64(2), affected)
+}
+
+func TestInsert_LastInsertIDs(t *testing.T) {
+	ids, err := dialects[MySQL].lastInsertIDs(5, 3)
+	require.Nil(t, err)
+	require.Equal(t, []int64{5, 6, 7}, ids)
+
+	ids, err = dialects[SQLite].lastInsertIDs(5, 3)
+	require.Nil(t, err)
+	require.Equal(t, []int64{3, 4, 5}, ids)
+
+	_, err = dialects[Generic].lastInsertIDs(5, 3)
+	require.Equal(t, ErrReturningUnsupported, err)
+}
+
+func TestInsert_SQLFromSelect(t *testing.T) {
+	testSQL(t,
+		"INSERT INTO archive (id, name) SELECT id,name FROM users WHERE (id > ?)",
+		[]interface{}{10},
+		Insert().
+			Into("archive").
+			Columns("id", "name").
+			FromSelect(Select("id", "name").From("users").Where(Gt("id", 10))),
+	)
+}
+
+func TestInsert_PostgresFromSelect(t *testing.T) {
+	sel := New(WithDialect(Postgres)).
+		Select("id", "name").
+		From("users").
+		Where("id > ? AND name = ?", 10, "a")
+	testSQL(t,
+		"INSERT INTO archive (id, name) SELECT id,name FROM users WHERE id > $1 AND name = $2 RETURNING id",
+		[]interface{}{10, "a"},
+		InsertStmt{dialect: Postgres}.
+			Into("archive").
+			Columns("id", "name").
+			FromSelect(sel).
+			Returning("id"),
+	)
+}
+
+func TestInsert_InvalidFromSelectValues(t *testing.T) {
+	_, _, err := Insert().
+		Into("archive").
+		Columns("id").
+		Values(1).
+		FromSelect(Select("id").From("users")).
+		SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+}
@@ -0,0 +1,388 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"sort"
+	"strings"
+)
+
+// Schema describes the tables in a database.
+type Schema struct {
+	Tables []Table
+}
+
+// Table returns the table with the given name.
+func (s Schema) Table(name string) (Table, bool) {
+	for _, t := range s.Tables {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Table{}, false
+}
+
+// Table describes a table.
+type Table struct {
+	Name        string
+	Columns     []Column
+	PrimaryKey  []string // Primary key columns in key order.
+	Indexes     []Index  // Indexes other than the primary key.
+	ForeignKeys []ForeignKey
+}
+
+// Column describes a column. Type is the declared type including any length
+// or precision, such as "character varying(255)" on Postgres, "varchar(255)"
+// on MySQL and the type as written in the table definition on SQLite.
+type Column struct {
+	Name     string
+	Type     string
+	Nullable bool
+	Default  string // Default expression, empty if there is no default.
+}
+
+// Index describes an index.
+type Index struct {
+	Name    string
+	Columns []string
+	Unique  bool
+}
+
+// ForeignKey describes a foreign key. Name is empty on SQLite, which does not
+// report constraint names.
+type ForeignKey struct {
+	Name       string
+	Columns    []string
+	RefTable   string
+	RefColumns []string
+	OnUpdate   string
+	OnDelete   string
+}
+
+// Inspect reads the schema of the database. Postgres and MySQL are read from
+// information_schema and the catalog for the current schema or database,
+// SQLite is read from sqlite_master and PRAGMA statements. Generic databases
+// are not supported.
+func Inspect(ctx context.Context, d DB) (Schema, error) {
+	var inspect func(context.Context, DB, string) (Table, error)
+	var tables []string
+	var err error
+	switch DialectOf(d) {
+	case SQLite:
+		inspect = inspectSQLite
+		err = d.Query(ctx, d.Select("name").
+			From("sqlite_master").
+			Where("type = ? AND name NOT LIKE ?", "table", "sqlite_%").
+			OrderBy("name")).Decode(&tables)
+	case Postgres, MySQL:
+		inspect = inspectInfoSchema
+		err = d.Query(ctx, d.Select("table_name AS name").
+			From("information_schema.tables").
+			Where("table_schema = "+currentSchema(d)+" AND table_type = ?", "BASE TABLE").
+			OrderBy("name")).Decode(&tables)
+	default:
+		return Schema{}, unsupported("Inspect")
+	}
+	if err != nil {
+		return Schema{}, err
+	}
+
+	out := Schema{Tables: make([]Table, 0, len(tables))}
+	for _, name := range tables {
+		t, err := inspect(ctx, d, name)
+		if err != nil {
+			return Schema{}, err
+		}
+		out.Tables = append(out.Tables, t)
+	}
+	return out, nil
+}
+
+func inspectSQLite(ctx context.Context, d DB, name string) (Table, error) {
+	t := Table{Name: name}
+	quoted := sqliteQuote(name)
+
+	cols, err := sqliteColumns(ctx, d, name)
+	if err != nil {
+		return t, err
+	}
+	t.PrimaryKey = sqlitePrimaryKey(cols)
+	for _, c := range cols {
+		t.Columns = append(t.Columns, Column{
+			Name:     c.Name,
+			Type:     c.Type,
+			Nullable: !c.NotNull && c.PK == 0,
+			Default:  c.Default,
+		})
+	}
+
+	var indexes []struct {
+		Seq     int    `db:"seq"`
+		Name    string `db:"name"`
+		Unique  bool   `db:"unique"`
+		Origin  string `db:"origin"`
+		Partial bool   `db:"partial"`
+	}
+	if err := d.Query(ctx, Raw("PRAGMA index_list("+quoted+")")).Decode(&indexes); err != nil {
+		return t, err
+	}
+	sort.Slice(indexes, func(i, j int) bool { return indexes[i].Name < indexes[j].Name })
+	for _, idx := range indexes {
+		if idx.Origin == "pk" {
+			continue
+		}
+		var info []struct {
+			SeqNo int    `db:"seqno"`
+			CID   int    `db:"cid"`
+			Name  string `db:"name"`
+		}
+		q := Raw("PRAGMA index_info(" + sqliteQuote(idx.Name) + ")")
+		if err := d.Query(ctx, q).Decode(&info); err != nil {
+			return t, err
+		}
+		sort.Slice(info, func(i, j int) bool { return info[i].SeqNo < info[j].SeqNo })
+		index := Index{Name: idx.Name, Unique: idx.Unique}
+		for _, c := range info {
+			index.Columns = append(index.Columns, c.Name)
+		}
+		t.Indexes = append(t.Indexes, index)
+	}
+
+	var fks []struct {
+		ID       int    `db:"id"`
+		Seq      int    `db:"seq"`
+		Table    string `db:"table"`
+		From     string `db:"from"`
+		To       string `db:"to"`
+		OnUpdate string `db:"on_update"`
+		OnDelete string `db:"on_delete"`
+		Match    string `db:"match"`
+	}
+	if err := d.Query(ctx, Raw("PRAGMA foreign_key_list("+quoted+")")).Decode(&fks); err != nil {
+		return t, err
+	}
+	sort.Slice(fks, func(i, j int) bool {
+		if fks[i].ID != fks[j].ID {
+			return fks[i].ID < fks[j].ID
+		}
+		return fks[i].Seq < fks[j].Seq
+	})
+	var refKey []string
+	for i, fk := range fks {
+		if i == 0 || fks[i-1].ID != fk.ID {
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{
+				RefTable: fk.Table,
+				OnUpdate: fk.OnUpdate,
+				OnDelete: fk.OnDelete,
+			})
+			refKey = nil
+		}
+		// A foreign key without columns, such as REFERENCES accounts,
+		// references the primary key of the table.
+		if fk.To == "" && refKey == nil {
+			refCols, err := sqliteColumns(ctx, d, fk.Table)
+			if err != nil {
+				return t, err
+			}
+			refKey = sqlitePrimaryKey(refCols)
+		}
+		if fk.To == "" && fk.Seq < len(refKey) {
+			fk.To = refKey[fk.Seq]
+		}
+		last := &t.ForeignKeys[len(t.ForeignKeys)-1]
+		last.Columns = append(last.Columns, fk.From)
+		last.RefColumns = append(last.RefColumns, fk.To)
+	}
+	return t, nil
+}
+
+type sqliteColumn struct {
+	CID     int    `db:"cid"`
+	Name    string `db:"name"`
+	Type    string `db:"type"`
+	NotNull bool   `db:"notnull"`
+	Default string `db:"dflt_value"`
+	PK      int    `db:"pk"`
+}
+
+// sqliteColumns returns the columns of a table in order.
+func sqliteColumns(ctx context.Context, d DB, name string) ([]sqliteColumn, error) {
+	var cols []sqliteColumn
+	if err := d.Query(ctx, Raw("PRAGMA table_info("+sqliteQuote(name)+")")).Decode(&cols); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(cols, func(i, j int) bool { return cols[i].CID < cols[j].CID })
+	return cols, nil
+}
+
+// sqlitePrimaryKey returns the primary key columns in key order.
+func sqlitePrimaryKey(cols []sqliteColumn) []string {
+	pk := make([]sqliteColumn, 0, len(cols))
+	for _, c := range cols {
+		if c.PK > 0 {
+			pk = append(pk, c)
+		}
+	}
+	sort.SliceStable(pk, func(i, j int) bool { return pk[i].PK < pk[j].PK })
+	var out []string
+	for _, c := range pk {
+		out = append(out, c.Name)
+	}
+	return out
+}
+
+func sqliteQuote(name string) string {
+	return `"` + strings.Replace(name, `"`, `""`, -1) + `"`
+}
+
+func inspectInfoSchema(ctx context.Context, d DB, name string) (Table, error) {
+	t := Table{Name: name}
+	schema := currentSchema(d)
+
+	// Postgres reports the type without its length or precision in data_type,
+	// format_type includes them as column_type does on MySQL.
+	typeColumn := "column_type"
+	if DialectOf(d) == Postgres {
+		typeColumn = "(SELECT format_type(a.atttypid, a.atttypmod) FROM pg_attribute a " +
+			"WHERE a.attrelid = (quote_ident(table_schema) || '.' || quote_ident(table_name))::regclass " +
+			"AND a.attname = column_name)"
+	}
+	var cols []struct {
+		Name     string `db:"name"`
+		Type     string `db:"type"`
+		Nullable string `db:"nullable"`
+		Default  string `db:"dflt"`
+	}
+	err := d.Query(ctx, d.Select(
+		"column_name AS name",
+		typeColumn+" AS type",
+		"is_nullable AS nullable",
+		"column_default AS dflt",
+	).
+		From("information_schema.columns").
+		Where("table_schema = "+schema+" AND table_name = ?", name).
+		OrderBy("ordinal_position")).Decode(&cols)
+	if err != nil {
+		return t, err
+	}
+	for _, c := range cols {
+		t.Columns = append(t.Columns, Column{
+			Name:     c.Name,
+			Type:     c.Type,
+			Nullable: c.Nullable == "YES",
+			Default:  c.Default,
+		})
+	}
+
+	err = d.Query(ctx, d.Select("kcu.column_name AS name").
+		From("information_schema.table_constraints tc").
+		InnerJoin("information_schema.key_column_usage kcu",
+			"kcu.constraint_name = tc.constraint_name AND "+
+				"kcu.table_schema = tc.table_schema AND kcu.table_name = tc.table_name").
+		Where("tc.table_schema = "+schema+" AND tc.table_name = ? AND tc.constraint_type = ?",
+			name, "PRIMARY KEY").
+		OrderBy("kcu.ordinal_position")).Decode(&t.PrimaryKey)
+	if err != nil {
+		return t, err
+	}
+
+	var indexes []struct {
+		Name   string `db:"name"`
+		Column string `db:"column_name"`
+		Unique bool   `db:"uniq"`
+	}
+	var q SelectStmt
+	if DialectOf(d) == MySQL {
+		q = d.Select("index_name AS name", "column_name AS column_name", "non_unique = 0 AS uniq").
+			From("information_schema.statistics").
+			Where("table_schema = "+schema+" AND table_name = ? AND index_name != ?", name, "PRIMARY").
+			OrderBy("index_name", "seq_in_index")
+	} else {
+		q = d.Select("i.relname AS name", "a.attname AS column_name", "ix.indisunique AS uniq").
+			From("pg_class t").
+			InnerJoin("pg_index ix", "ix.indrelid = t.oid").
+			InnerJoin("pg_class i", "i.oid = ix.indexrelid").
+			InnerJoin("pg_namespace n", "n.oid = t.relnamespace").
+			InnerJoin("LATERAL unnest(ix.indkey) WITH ORDINALITY AS k(attnum, ord)", "true").
+			InnerJoin("pg_attribute a", "a.attrelid = t.oid AND a.attnum = k.attnum").
+			Where("n.nspname = "+schema+" AND t.relname = ? AND NOT ix.indisprimary", name).
+			OrderBy("i.relname", "k.ord")
+	}
+	if err := d.Query(ctx, q).Decode(&indexes); err != nil {
+		return t, err
+	}
+	for i, idx := range indexes {
+		if i == 0 || indexes[i-1].Name != idx.Name {
+			t.Indexes = append(t.Indexes, Index{Name: idx.Name, Unique: idx.Unique})
+		}
+		last := &t.Indexes[len(t.Indexes)-1]
+		last.Columns = append(last.Columns, idx.Column)
+	}
+
+	var fks []struct {
+		Name      string `db:"name"`
+		Column    string `db:"column_name"`
+		RefTable  string `db:"ref_table"`
+		RefColumn string `db:"ref_column"`
+		OnUpdate  string `db:"on_update"`
+		OnDelete  string `db:"on_delete"`
+	}
+	// The referenced columns are found through the unique constraint which the
+	// foreign key references, matching columns by position.
+	err = d.Query(ctx, d.Select(
+		"kcu.constraint_name AS name",
+		"kcu.column_name AS column_name",
+		"ref.table_name AS ref_table",
+		"ref.column_name AS ref_column",
+		"rc.update_rule AS on_update",
+		"rc.delete_rule AS on_delete",
+	).
+		From("information_schema.referential_constraints rc").
+		InnerJoin("information_schema.key_column_usage kcu",
+			"kcu.constraint_schema = rc.constraint_schema AND kcu.constraint_name = rc.constraint_name").
+		InnerJoin("information_schema.key_column_usage ref",
+			"ref.constraint_schema = rc.unique_constraint_schema AND "+
+				"ref.constraint_name = rc.unique_constraint_name AND "+
+				"ref.ordinal_position = kcu.position_in_unique_constraint"+
+				mysqlRefTable(d)).
+		Where("rc.constraint_schema = "+schema+" AND kcu.table_name = ?", name).
+		OrderBy("kcu.constraint_name", "kcu.ordinal_position")).Decode(&fks)
+	if err != nil {
+		return t, err
+	}
+	for i, fk := range fks {
+		if i == 0 || fks[i-1].Name != fk.Name {
+			t.ForeignKeys = append(t.ForeignKeys, ForeignKey{
+				Name:     fk.Name,
+				RefTable: fk.RefTable,
+				OnUpdate: fk.OnUpdate,
+				OnDelete: fk.OnDelete,
+			})
+		}
+		last := &t.ForeignKeys[len(t.ForeignKeys)-1]
+		last.Columns = append(last.Columns, fk.Column)
+		last.RefColumns = append(last.RefColumns, fk.RefColumn)
+	}
+	return t, nil
+}
+
+// currentSchema returns the expression for the current schema or database.
+func currentSchema(d DB) string {
+	if DialectOf(d) == MySQL {
+		return "DATABASE()"
+	}
+	return "current_schema()"
+}
+
+// mysqlRefTable restricts the referenced key columns to the referenced table
+// on MySQL, where every primary key constraint is named PRIMARY.
+func mysqlRefTable(d DB) string {
+	if DialectOf(d) == MySQL {
+		return " AND ref.table_name = rc.referenced_table_name"
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspect_SQLite(t *testing.T) {
+	d, err := Open("sqlite3", "file:inspecttest?mode=memory&cache=shared")
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	for _, s := range []string{
+		`create table accounts (
+			id integer primary key,
+			name text not null default 'none',
+			region varchar(8),
+			unique (name, region)
+		)`,
+		`create table members (
+			account_id int not null,
+			user_id int not null,
+			role text,
+			primary key (account_id, user_id),
+			foreign key (account_id) references accounts (id) on delete cascade
+		)`,
+		`create index members_role on members (role, user_id)`,
+		`create table invites (account_id int references accounts, email text)`,
+	} {
+		require.Nil(t, d.Exec(ctx, Raw(s)).Err())
+	}
+
+	schema, err := Inspect(ctx, d)
+	require.Nil(t, err)
+	require.Len(t, schema.Tables, 3)
+
+	accounts, ok := schema.Table("accounts")
+	require.True(t, ok)
+	require.Equal(t, []Column{
+		{Name: "id", Type: "integer"},
+		{Name: "name", Type: "text", Default: "'none'"},
+		{Name: "region", Type: "varchar(8)", Nullable: true},
+	}, accounts.Columns)
+	require.Equal(t, []string{"id"}, accounts.PrimaryKey)
+	require.Equal(t, []Index{{
+		Name:    "sqlite_autoindex_accounts_1",
+		Columns: []string{"name", "region"},
+		Unique:  true,
+	}}, accounts.Indexes)
+	require.Nil(t, accounts.ForeignKeys)
+
+	members, ok := schema.Table("members")
+	require.True(t, ok)
+	require.Equal(t, []string{"account_id", "user_id"}, members.PrimaryKey)
+	require.Equal(t, []Index{{
+		Name:    "members_role",
+		Columns: []string{"role", "user_id"},
+	}}, members.Indexes)
+	require.Equal(t, []ForeignKey{{
+		Columns:    []string{"account_id"},
+		RefTable:   "accounts",
+		RefColumns: []string{"id"},
+		OnUpdate:   "NO ACTION",
+		OnDelete:   "CASCADE",
+	}}, members.ForeignKeys)
+
+	// A reference without columns is to the primary key.
+	invites, ok := schema.Table("invites")
+	require.True(t, ok)
+	require.Equal(t, []ForeignKey{{
+		Columns:    []string{"account_id"},
+		RefTable:   "accounts",
+		RefColumns: []string{"id"},
+		OnUpdate:   "NO ACTION",
+		OnDelete:   "NO ACTION",
+	}}, invites.ForeignKeys)
+
+	_, ok = schema.Table("missing")
+	require.False(t, ok)
+}
+
+func TestInspect_Generic(t *testing.T) {
+	_, err := Inspect(context.Background(), New(WithDialect(Generic)))
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
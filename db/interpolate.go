@@ -0,0 +1,143 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// maxInterpolateLen is the length above which strings and byte slices are
+// truncated by Interpolate.
+const maxInterpolateLen = 1024
+
+// Interpolate renders a statement with its values written inline as literals
+// for the dialect, for example to paste a logged query into a database shell.
+// Both '?' and '$n' placeholders are replaced. Strings and byte slices longer
+// than 1024 bytes are truncated, which is marked with a comment.
+//
+// Interpolate is only intended for debugging and logging. The output must
+// never be executed, use bound values instead.
+func Interpolate(dialect Dialect, s SQL) (string, error) {
+	return interpolate(dialect, s, maxInterpolateLen)
+}
+
+// interpolate renders a statement with its values written inline. Strings and
+// byte slices longer than limit bytes are truncated, unless limit is zero.
+func interpolate(dialect Dialect, s SQL, limit int) (string, error) {
+	sql, values, err := unbound(s)
+	if err != nil {
+		return "", err
+	}
+
+	var out strings.Builder
+	var next int
+	for _, t := range scanSQL(sql, dialects[dialect].backslashEscapes()) {
+		idx := -1
+		switch t.kind {
+		case tokenPlaceholder:
+			idx = next
+			next++
+		case tokenNumbered:
+			n, _ := strconv.Atoi(t.text[1:])
+			idx = n - 1
+		case tokenEscaped:
+			out.WriteString("?")
+			continue
+		default:
+			out.WriteString(t.text)
+			continue
+		}
+		if idx < 0 || idx >= len(values) {
+			return "", fmt.Errorf("sqlkit/db: no value for placeholder %s", t.text)
+		}
+		lit, err := literal(dialect, argValue(values[idx]), limit)
+		if err != nil {
+			return "", err
+		}
+		out.WriteString(lit)
+	}
+	return out.String(), nil
+}
+
+// literal returns value as an SQL literal for the dialect. Strings and byte
+// slices longer than limit bytes are truncated, unless limit is zero.
+func literal(dialect Dialect, value interface{}, limit int) (string, error) {
+	if v, ok := value.(driver.Valuer); ok {
+		var err error
+		if value, err = v.Value(); err != nil {
+			return "", err
+		}
+	}
+	value, err := driver.DefaultParameterConverter.ConvertValue(value)
+	if err != nil {
+		return "", err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		switch {
+		case dialect == SQLite && v:
+			return "1", nil
+		case dialect == SQLite:
+			return "0", nil
+		case v:
+			return "TRUE", nil
+		default:
+			return "FALSE", nil
+		}
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		s, suffix := truncate(v, limit)
+		s = strings.Replace(s, "'", "''", -1)
+		if dialect == MySQL {
+			s = strings.Replace(s, `\`, `\\`, -1)
+		}
+		return "'" + s + "'" + suffix, nil
+	case []byte:
+		b, suffix := v, ""
+		if limit > 0 && len(b) > limit/2 {
+			b, suffix = b[:limit/2], truncated(len(v))
+		}
+		if dialect == Postgres {
+			return `'\x` + hex.EncodeToString(b) + "'::bytea" + suffix, nil
+		}
+		return "X'" + hex.EncodeToString(b) + "'" + suffix, nil
+	case time.Time:
+		if dialect == MySQL {
+			return "'" + v.Format("2006-01-02 15:04:05.999999") + "'", nil
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.999999-07:00") + "'", nil
+	}
+	return "", fmt.Errorf("sqlkit/db: cannot interpolate value of type %T", value)
+}
+
+// truncate shortens s to limit bytes at a character boundary and returns the
+// comment marking the truncation. A zero limit leaves s unchanged.
+func truncate(s string, limit int) (string, string) {
+	if limit == 0 || len(s) <= limit {
+		return s, ""
+	}
+	end := limit
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end], truncated(len(s))
+}
+
+func truncated(n int) string {
+	return " /* truncated from " + strconv.Itoa(n) + " bytes */"
+}
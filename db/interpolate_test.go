@@ -0,0 +1,56 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInterpolate(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 6000, time.UTC)
+	q := SelectStmt{dialect: Postgres}.
+		Select("*").
+		From("users").
+		Where("name = ? AND data ?? 'k' AND note = '?'", "o'neil").
+		Where(In("id", []int{1, 2})).
+		Where("active = ? AND created < ? AND blob = ? AND deleted IS ? AND score > ?",
+			true, now, []byte{0xde, 0xad}, nil, 1.5)
+
+	out, err := Interpolate(Postgres, q)
+	require.NoError(t, err)
+	require.Equal(t,
+		"SELECT * FROM users WHERE ((name = 'o''neil' AND data ? 'k' AND note = '?' AND (id IN (1, 2))) AND "+
+			`active = TRUE AND created < '2018-01-02 03:04:05.000006+00:00' AND blob = '\xdead'::bytea AND deleted IS NULL AND score > 1.5)`,
+		out)
+
+	out, err = Interpolate(MySQL, RawWithValues(`SELECT ?, ?, ?, ?`, `a\b`, false, []byte{0xde, 0xad}, now))
+	require.NoError(t, err)
+	require.Equal(t, `SELECT 'a\\b', FALSE, X'dead', '2018-01-02 03:04:05.000006'`, out)
+
+	out, err = Interpolate(SQLite, RawWithValues(`SELECT ?, ?`, true, sql.NullString{}))
+	require.NoError(t, err)
+	require.Equal(t, `SELECT 1, NULL`, out)
+}
+
+func TestInterpolate_Numbered(t *testing.T) {
+	out, err := Interpolate(Postgres, RawWithValues("SELECT $2, $1", 1, "a"))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT 'a', 1", out)
+
+	_, err = Interpolate(Postgres, RawWithValues("SELECT $2", 1))
+	require.Error(t, err)
+}
+
+func TestInterpolate_Truncate(t *testing.T) {
+	out, err := Interpolate(Postgres, RawWithValues("SELECT ?", strings.Repeat("a", 2000)))
+	require.NoError(t, err)
+	require.Equal(t, "SELECT '"+strings.Repeat("a", 1024)+"' /* truncated from 2000 bytes */", out)
+}
@@ -0,0 +1,236 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type keyset struct {
+	values []interface{}
+	before bool
+}
+
+// After configures keyset pagination to return the rows which come after the
+// row encoded in cursor. The condition is generated from the ORDER BY columns
+// when the statement is rendered, so the ordering should end with a unique
+// column, such as the primary key, and the key columns should not be NULL.
+// Orderings using NULLS FIRST or NULLS LAST are rejected with ErrUnsupported.
+func (q SelectStmt) After(cursor string) SelectStmt {
+	return q.keysetCursor(cursor, false)
+}
+
+// Before configures keyset pagination to return the rows which come before the
+// row encoded in cursor. The ORDER BY directions are reversed so that LIMIT
+// selects the rows closest to the cursor, which means that rows are returned in
+// reverse order and should be reversed by the caller.
+func (q SelectStmt) Before(cursor string) SelectStmt {
+	return q.keysetCursor(cursor, true)
+}
+
+func (q SelectStmt) keysetCursor(cursor string, before bool) SelectStmt {
+	values, err := decodeCursor(cursor)
+	if err != nil {
+		q.err = err
+		return q
+	}
+	q.keyset = &keyset{values: values, before: before}
+	return q
+}
+
+// Cursor returns an opaque URL safe cursor for row, which is usually the last
+// row of a page decoded by the statement. The values of the ORDER BY columns
+// are read from row using the encoder.
+func (q SelectStmt) Cursor(row interface{}) (string, error) {
+	names, values, err := q.encoder.Encode(row)
+	if err != nil {
+		return "", err
+	}
+	byName := make(map[string]interface{}, len(names))
+	for i, name := range names {
+		byName[name] = values[i]
+	}
+
+	keys := make([]interface{}, len(q.orderBy))
+	for i, o := range q.orderBy {
+		col, _, err := parseOrder(o)
+		if err != nil {
+			return "", err
+		}
+		col = col[strings.LastIndex(col, ".")+1:]
+		col = strings.Trim(col, "\"`")
+		value, ok := byName[col]
+		if !ok {
+			return "", fmt.Errorf("%w: no field for column %s", ErrInvalidCursor, col)
+		}
+		keys[i] = value
+	}
+	return encodeCursor(keys)
+}
+
+// applyKeyset adds the keyset condition to the where clause and reverses the
+// ordering for Before.
+func (q SelectStmt) applyKeyset() SelectStmt {
+	if q.keyset == nil {
+		return q
+	}
+	ks := q.keyset
+	q.keyset = nil
+	if len(ks.values) != len(q.orderBy) {
+		q.err = fmt.Errorf("%w: cursor does not match ORDER BY", ErrInvalidCursor)
+		return q
+	}
+
+	// For columns (a, b) the condition is (a > ?) OR (a = ? AND b > ?) with
+	// the comparison reversed for descending columns.
+	var cond, prefix Statement
+	orderBy := make([]string, len(q.orderBy))
+	for i, o := range q.orderBy {
+		col, desc, err := parseOrder(o)
+		if err != nil {
+			q.err = err
+			return q
+		}
+		op := lt
+		if desc == ks.before {
+			op = gt
+		}
+		cond = cond.Or(prefix.And(stmt(op, col, ks.values[i])))
+		prefix = prefix.And(Eq(col, ks.values[i]))
+
+		orderBy[i] = o
+		if ks.before {
+			if desc {
+				orderBy[i] = col + " ASC"
+			} else {
+				orderBy[i] = col + " DESC"
+			}
+		}
+	}
+	q.orderBy = orderBy
+	q.whereClause = q.whereClause.where(and, cond)
+	return q
+}
+
+// parseOrder splits an ORDER BY expression into the column and whether it is
+// descending. NULLS FIRST and NULLS LAST are rejected since rows with NULL
+// keys cannot be compared with the cursor.
+func parseOrder(order string) (string, bool, error) {
+	order = strings.TrimSpace(order)
+	for _, f := range strings.Fields(order) {
+		if strings.EqualFold(f, "NULLS") {
+			return "", false, unsupported("keyset pagination with NULLS FIRST or LAST")
+		}
+	}
+	i := strings.LastIndexAny(order, " \t\n")
+	if i < 0 {
+		return order, false, nil
+	}
+	switch strings.ToUpper(order[i+1:]) {
+	case "DESC":
+		return strings.TrimSpace(order[:i]), true, nil
+	case "ASC":
+		return strings.TrimSpace(order[:i]), false, nil
+	}
+	return order, false, nil
+}
+
+// cursorValue is a typed value in a cursor. Types are kept so that integers,
+// times and byte slices compare correctly after decoding.
+type cursorValue struct {
+	Type  string          `json:"t"`
+	Value json.RawMessage `json:"v,omitempty"`
+}
+
+func encodeCursor(values []interface{}) (string, error) {
+	out := make([]cursorValue, len(values))
+	for i, value := range values {
+		if v, ok := value.(driver.Valuer); ok {
+			var err error
+			if value, err = v.Value(); err != nil {
+				return "", err
+			}
+		}
+		value, err := driver.DefaultParameterConverter.ConvertValue(value)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		var typ string
+		switch value.(type) {
+		case nil:
+			typ = "n"
+		case int64:
+			typ = "i"
+		case float64:
+			typ = "f"
+		case bool:
+			typ = "b"
+		case string:
+			typ = "s"
+		case []byte:
+			typ = "x"
+		case time.Time:
+			typ = "t"
+		}
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return "", err
+		}
+		out[i] = cursorValue{Type: typ, Value: raw}
+	}
+	data, err := json.Marshal(out)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(cursor string) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var raw []cursorValue
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	values := make([]interface{}, len(raw))
+	for i, r := range raw {
+		var dest interface{}
+		switch r.Type {
+		case "n":
+			continue
+		case "i":
+			dest = new(int64)
+		case "f":
+			dest = new(float64)
+		case "b":
+			dest = new(bool)
+		case "s":
+			dest = new(string)
+		case "x":
+			dest = new([]byte)
+		case "t":
+			dest = new(time.Time)
+		default:
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidCursor, r.Type)
+		}
+		dec := json.NewDecoder(bytes.NewReader(r.Value))
+		if err := dec.Decode(dest); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+		}
+		values[i] = reflect.ValueOf(dest).Elem().Interface()
+	}
+	return values, nil
+}
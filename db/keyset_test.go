@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyset_Cursor(t *testing.T) {
+	now := time.Date(2018, 1, 2, 3, 4, 5, 6, time.UTC)
+	cursor, err := encodeCursor([]interface{}{1, "a", now, []byte("b"), nil, 1.5, true})
+	require.NoError(t, err)
+	values, err := decodeCursor(cursor)
+	require.NoError(t, err)
+	require.Equal(t, []interface{}{int64(1), "a", now, []byte("b"), nil, 1.5, true}, values)
+
+	_, err = decodeCursor("not a cursor")
+	require.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestKeyset_SQLAfter(t *testing.T) {
+	cursor, err := encodeCursor([]interface{}{10, 5})
+	require.NoError(t, err)
+	testSQL(t,
+		"SELECT * FROM users WHERE (active = $1 AND ((score < $2) OR ((score = $3) AND (id > $4)))) ORDER BY score DESC, id LIMIT 10",
+		[]interface{}{true, int64(10), int64(10), int64(5)},
+		SelectStmt{dialect: Postgres}.
+			Select("*").
+			From("users").
+			Where("active = ?", true).
+			OrderBy("score DESC", "id").
+			After(cursor).
+			Limit(10),
+	)
+}
+
+func TestKeyset_SQLBefore(t *testing.T) {
+	cursor, err := encodeCursor([]interface{}{10, 5})
+	require.NoError(t, err)
+	testSQL(t,
+		"SELECT * FROM users WHERE ((score > ?) OR ((score = ?) AND (id < ?))) ORDER BY score ASC, id DESC LIMIT 10",
+		[]interface{}{int64(10), int64(10), int64(5)},
+		Select("*").
+			From("users").
+			OrderBy("score DESC", "id").
+			Before(cursor).
+			Limit(10),
+	)
+}
+
+func TestKeyset_Mismatch(t *testing.T) {
+	cursor, err := encodeCursor([]interface{}{10})
+	require.NoError(t, err)
+	_, _, err = Select("*").From("users").OrderBy("score", "id").After(cursor).SQL()
+	require.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestKeyset_NullsOrder(t *testing.T) {
+	cursor, err := encodeCursor([]interface{}{10})
+	require.NoError(t, err)
+	_, _, err = Select("*").From("users").OrderBy("score DESC NULLS LAST").After(cursor).SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, err = Select("*").From("users").OrderBy("score nulls first").Cursor(struct {
+		Score int `db:"score"`
+	}{1})
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestKeyset_CountQuery(t *testing.T) {
+	cursor, err := encodeCursor([]interface{}{10})
+	require.NoError(t, err)
+	testSQL(t,
+		"SELECT COUNT(*) FROM (SELECT * FROM users WHERE active = ?) AS count_query",
+		[]interface{}{true},
+		countQuery(Select("*").From("users").Where("active = ?", true).OrderBy("id").After(cursor)),
+	)
+}
+
+func TestKeyset_Paginate(t *testing.T) {
+	type user struct {
+		ID int `db:"id"`
+	}
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+		for i := 1; i <= 5; i++ {
+			require.NoError(t, db.Exec(ctx, db.Insert().Into("users").Columns("id").Values(i)).Err())
+		}
+		q := db.Select("*").From("users").OrderBy("users.id DESC").Limit(2)
+
+		var page []user
+		require.NoError(t, db.Query(ctx, q).Decode(&page))
+		require.Equal(t, []user{{5}, {4}}, page)
+
+		cursor, err := q.Cursor(page[len(page)-1])
+		require.NoError(t, err)
+		page = nil
+		require.NoError(t, db.Query(ctx, q.After(cursor)).Decode(&page))
+		require.Equal(t, []user{{3}, {2}}, page)
+
+		cursor, err = q.Cursor(page[0])
+		require.NoError(t, err)
+		page = nil
+		require.NoError(t, db.Query(ctx, q.Before(cursor)).Decode(&page))
+		require.Equal(t, []user{{4}, {5}}, page)
+	})
+}
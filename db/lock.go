@@ -0,0 +1,45 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+// Row locking clauses are rendered on Postgres and MySQL 8. SQLite locks the
+// whole database for writes and has no row locking, so the clauses are dropped
+// from the statement and have no effect. A locking clause cannot be combined
+// with set operations such as UNION, and Of, SkipLocked and NoWait require
+// ForUpdate or ForShare.
+
+// ForUpdate configures a FOR UPDATE locking clause. Locks are only held for the
+// duration of a transaction so the select should be run inside a TX.
+func (q SelectStmt) ForUpdate() SelectStmt {
+	q.lock = "UPDATE"
+	return q
+}
+
+// ForShare configures a FOR SHARE locking clause.
+func (q SelectStmt) ForShare() SelectStmt {
+	q.lock = "SHARE"
+	return q
+}
+
+// Of restricts the locking clause to the given tables.
+func (q SelectStmt) Of(tables ...string) SelectStmt {
+	q.lockOf = append([]string(nil), tables...)
+	return q
+}
+
+// SkipLocked configures the locking clause to skip rows that are already
+// locked instead of waiting.
+func (q SelectStmt) SkipLocked() SelectStmt {
+	q.lockWait = "SKIP LOCKED"
+	return q
+}
+
+// NoWait configures the locking clause to return an error if a row is already
+// locked instead of waiting.
+func (q SelectStmt) NoWait() SelectStmt {
+	q.lockWait = "NOWAIT"
+	return q
+}
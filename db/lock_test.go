@@ -0,0 +1,99 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLock_SQLPostgres(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM jobs WHERE state = $1 ORDER BY id LIMIT 1 FOR UPDATE SKIP LOCKED",
+		[]interface{}{"queued"},
+		SelectStmt{dialect: Postgres}.
+			Select("*").
+			From("jobs").
+			Where("state = ?", "queued").
+			OrderBy("id").
+			Limit(1).
+			ForUpdate().
+			SkipLocked(),
+	)
+}
+
+func TestLock_SQLMySQL(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM jobs INNER JOIN users ON users.id = jobs.user_id FOR SHARE OF jobs NOWAIT",
+		nil,
+		SelectStmt{dialect: MySQL}.
+			Select("*").
+			From("jobs").
+			InnerJoin("users", "users.id = jobs.user_id").
+			ForShare().
+			Of("jobs").
+			NoWait(),
+	)
+}
+
+func TestLock_SQLSQLite(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM jobs LIMIT 1",
+		nil,
+		SelectStmt{dialect: SQLite}.
+			Select("*").
+			From("jobs").
+			Limit(1).
+			ForUpdate().
+			SkipLocked(),
+	)
+}
+
+func TestLock_Invalid(t *testing.T) {
+	for _, q := range []SelectStmt{
+		Select("id").From("jobs").SkipLocked(),
+		Select("id").From("jobs").NoWait(),
+		Select("id").From("jobs").Of("jobs"),
+	} {
+		_, _, err := q.SQL()
+		require.Equal(t, ErrStatementInvalid, err)
+	}
+
+	_, _, err := SelectStmt{dialect: Postgres}.Select("id").From("a").
+		Union(Select("id").From("b")).
+		ForUpdate().
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestLock_DebugWarning(t *testing.T) {
+	var warnings int
+	logger := func(s SQL) {
+		if _, _, err := s.SQL(); errors.Is(err, ErrLockOutsideTX) {
+			warnings++
+		}
+	}
+	d, err := Open("sqlite3", "file:locktest?mode=memory&cache=shared", WithDebug(), WithLogger(logger))
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	err = d.Exec(ctx, Raw("create table jobs (id int primary key)")).Err()
+	require.Nil(t, err)
+
+	err = d.Query(ctx, d.Select("*").From("jobs").ForUpdate()).Err()
+	require.Nil(t, err)
+	require.Equal(t, 1, warnings)
+
+	err = d.TX(ctx, func(ctx context.Context) error {
+		return d.Query(ctx, d.Select("*").From("jobs").ForUpdate()).Err()
+	})
+	require.Nil(t, err)
+	require.Equal(t, 1, warnings)
+}
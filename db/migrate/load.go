@@ -0,0 +1,69 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"strconv"
+)
+
+var fileName = regexp.MustCompile(`^(\d+)_(.+)\.(up|down)\.sql$`)
+
+// Load reads SQL migrations from dir in fsys, which can be embedded using
+// go:embed. Files are named NNN_name.up.sql with an optional matching
+// NNN_name.down.sql, where NNN is the version. Other files are ignored. Each
+// file is executed using Script.
+func Load(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	var versions []int64
+	for _, e := range entries {
+		match := fileName.FindStringSubmatch(e.Name())
+		if e.IsDir() || match == nil {
+			continue
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("sqlkit/migrate: invalid version %s: %w", e.Name(), err)
+		}
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+			versions = append(versions, version)
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicateVersion, e.Name())
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		fn := Script(string(data))
+		if match[3] == "up" {
+			m.Up = fn
+		} else {
+			m.Down = fn
+		}
+	}
+
+	out := make([]Migration, 0, len(versions))
+	for _, v := range versions {
+		m := byVersion[v]
+		if m.Up == nil {
+			return nil, fmt.Errorf("sqlkit/migrate: missing up migration for %d_%s", m.Version, m.Name)
+		}
+		out = append(out, *m)
+	}
+	return out, nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package migrate
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"sql/001_users.up.sql": {Data: []byte(
+			"create table users (id integer primary key, name text);\n" +
+				"insert into users (id, name) values (1, 'a;b');\n",
+		)},
+		"sql/001_users.down.sql":  {Data: []byte("drop table users;")},
+		"sql/002_groups.up.sql":   {Data: []byte("create table groups (id integer primary key);")},
+		"sql/README.md":           {Data: []byte("ignored")},
+		"sql/003_broken.down.sql": {Data: []byte("select 1;")},
+	}
+	_, err := Load(fsys, "sql")
+	require.Error(t, err)
+
+	delete(fsys, "sql/003_broken.down.sql")
+	migrations, err := Load(fsys, "sql")
+	require.NoError(t, err)
+	require.Len(t, migrations, 2)
+	require.Equal(t, int64(1), migrations[0].Version)
+	require.Equal(t, "users", migrations[0].Name)
+	require.NotNil(t, migrations[0].Down)
+	require.Nil(t, migrations[1].Down)
+
+	ctx := context.Background()
+	d := open(t, "load")
+	defer d.Close()
+	m, err := New(d, migrations)
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+	require.Equal(t, []string{"groups", "schema_migrations", "users"}, tables(t, d))
+
+	var name string
+	require.NoError(t, d.Query(ctx, d.Select("name").From("users")).Decode(&name))
+	require.Equal(t, "a;b", name)
+}
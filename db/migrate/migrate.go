@@ -0,0 +1,369 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+// Package migrate applies versioned schema migrations using the db package.
+//
+// Migrations are applied in version order and each one runs in its own
+// transaction along with the update to the migrations table. A lock is held
+// until the transaction ends so that multiple instances running migrations at
+// the same time apply each migration once:
+//
+//   - Postgres uses pg_advisory_xact_lock inside the transaction.
+//   - MySQL uses GET_LOCK and RELEASE_LOCK on a separate connection, which is
+//     released after the transaction commits or rolls back. The DB must
+//     provide Conn, as the DB returned by db.Open does. DDL statements commit
+//     implicitly on MySQL so a failed migration may be partially applied.
+//   - SQLite takes the database write lock by writing to the migrations table.
+//
+// No lock is taken for the generic dialect.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"time"
+
+	"github.com/colinjfw/sqlkit/db"
+)
+
+var (
+	// ErrDuplicateVersion is returned when two migrations share a version.
+	ErrDuplicateVersion = errors.New("sqlkit/migrate: duplicate version")
+	// ErrIrreversible is returned when rolling back a migration without a
+	// down migration.
+	ErrIrreversible = errors.New("sqlkit/migrate: migration has no down")
+	// ErrUnknownVersion is returned when rolling back a version which has been
+	// applied but is not in the list of migrations.
+	ErrUnknownVersion = errors.New("sqlkit/migrate: unknown version")
+	// ErrLockFailed is returned when the migration lock cannot be taken.
+	ErrLockFailed = errors.New("sqlkit/migrate: could not take lock")
+)
+
+// Func is a migration step. The context is the migration transaction and
+// should be passed to all queries.
+type Func func(ctx context.Context, d db.DB) error
+
+// Migration is a single versioned migration.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      Func
+	Down    Func // Optional, required to roll back the migration.
+}
+
+// SQL returns a migration which executes the up and down scripts using
+// Script. An empty down leaves the migration irreversible.
+func SQL(version int64, name, up, down string) Migration {
+	m := Migration{Version: version, Name: name, Up: Script(up)}
+	if down != "" {
+		m.Down = Script(down)
+	}
+	return m
+}
+
+// Exec returns a Func which executes each statement in order.
+func Exec(statements ...string) Func {
+	return func(ctx context.Context, d db.DB) error {
+		for _, s := range statements {
+			if err := d.Exec(ctx, db.Raw(s)).Err(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Script returns a Func which splits the script into statements using
+// db.Split for the dialect of the database and executes them in order.
+func Script(script string) Func {
+	return func(ctx context.Context, d db.DB) error {
+		return Exec(db.Split(db.DialectOf(d), script)...)(ctx, d)
+	}
+}
+
+// Status describes a migration and whether it has been applied. Migrations
+// which have been applied but are not known to the migrator are included
+// without Up and Down funcs.
+type Status struct {
+	Migration
+	Applied   bool
+	AppliedAt time.Time
+}
+
+// Option configures a Migrator.
+type Option func(m *Migrator)
+
+// WithTable configures the table used to record applied migrations. The
+// default is schema_migrations.
+func WithTable(table string) Option {
+	return func(m *Migrator) { m.table = table }
+}
+
+// Migrator applies and rolls back migrations.
+type Migrator struct {
+	db         db.DB
+	table      string
+	migrations []Migration
+}
+
+// New returns a Migrator for the migrations, which are sorted by version.
+func New(d db.DB, migrations []Migration, opts ...Option) (*Migrator, error) {
+	m := &Migrator{
+		db:         d,
+		table:      "schema_migrations",
+		migrations: append([]Migration(nil), migrations...),
+	}
+	for _, o := range opts {
+		o(m)
+	}
+	sort.Slice(m.migrations, func(i, j int) bool {
+		return m.migrations[i].Version < m.migrations[j].Version
+	})
+	for i := 1; i < len(m.migrations); i++ {
+		if m.migrations[i].Version == m.migrations[i-1].Version {
+			return nil, fmt.Errorf("%w: %d", ErrDuplicateVersion, m.migrations[i].Version)
+		}
+	}
+	return m, nil
+}
+
+// Up applies all pending migrations in version order.
+func (m *Migrator) Up(ctx context.Context) error {
+	return m.UpTo(ctx, -1)
+}
+
+// UpTo applies pending migrations up to and including version. A negative
+// version applies all pending migrations.
+func (m *Migrator) UpTo(ctx context.Context, version int64) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, mig := range m.migrations {
+		if version >= 0 && mig.Version > version {
+			break
+		}
+		if _, ok := applied[mig.Version]; ok {
+			continue
+		}
+		if err := m.run(ctx, mig, true); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Down rolls back applied migrations with a version greater than version in
+// reverse order. A version of 0 rolls back every migration.
+func (m *Migrator) Down(ctx context.Context, version int64) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	known := make(map[int64]bool, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = true
+	}
+	for v := range applied {
+		if v > version && !known[v] {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, v)
+		}
+	}
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.Version <= version {
+			break
+		}
+		if _, ok := applied[mig.Version]; !ok {
+			continue
+		}
+		if mig.Down == nil {
+			return fmt.Errorf("%w: %d_%s", ErrIrreversible, mig.Version, mig.Name)
+		}
+		if err := m.run(ctx, mig, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Status returns every known or applied migration in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Migration: mig}
+		if r, ok := applied[mig.Version]; ok {
+			s.Applied, s.AppliedAt = true, r.AppliedAt
+			delete(applied, mig.Version)
+		}
+		out = append(out, s)
+	}
+	for _, r := range applied {
+		out = append(out, Status{
+			Migration: Migration{Version: r.Version, Name: r.Name},
+			Applied:   true,
+			AppliedAt: r.AppliedAt,
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Version < out[j].Version })
+	return out, nil
+}
+
+type record struct {
+	Version   int64     `db:"version"`
+	Name      string    `db:"name"`
+	AppliedAt time.Time `db:"applied_at"`
+}
+
+// applied creates the migrations table if needed and returns the applied
+// migrations by version.
+func (m *Migrator) applied(ctx context.Context) (map[int64]record, error) {
+	if err := m.db.Exec(ctx, m.createTable()).Err(); err != nil {
+		return nil, err
+	}
+	var records []record
+	err := m.db.Query(ctx, m.db.Select("version", "name", "applied_at").
+		From(m.table)).Decode(&records)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int64]record, len(records))
+	for _, r := range records {
+		out[r.Version] = r
+	}
+	return out, nil
+}
+
+// run applies or rolls back a migration in a transaction holding the lock. The
+// migration is checked again once the lock is held in case another instance
+// has run it.
+func (m *Migrator) run(ctx context.Context, mig Migration, up bool) error {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return fmt.Errorf("sqlkit/migrate: %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	err = m.db.TX(ctx, func(ctx context.Context) error {
+		if err := m.lockTX(ctx); err != nil {
+			return err
+		}
+
+		var count int
+		err := m.db.Query(ctx, m.db.Select("count(*)").
+			From(m.table).
+			Where("version = ?", mig.Version)).Decode(&count)
+		if err != nil {
+			return err
+		}
+		if (count > 0) == up {
+			return nil
+		}
+
+		if !up {
+			if err := mig.Down(ctx, m.db); err != nil {
+				return err
+			}
+			return m.db.Exec(ctx, m.db.Delete().
+				From(m.table).
+				Where("version = ?", mig.Version)).Err()
+		}
+		if err := mig.Up(ctx, m.db); err != nil {
+			return err
+		}
+		return m.db.Exec(ctx, m.db.Insert().
+			Into(m.table).
+			Columns("version", "name", "applied_at").
+			Values(mig.Version, mig.Name, time.Now().UTC())).Err()
+	})
+	// The lock is released once the transaction has ended so that another
+	// instance sees the committed migrations table.
+	if uerr := unlock(); err == nil {
+		err = uerr
+	}
+	if err != nil {
+		return fmt.Errorf("sqlkit/migrate: %d_%s: %w", mig.Version, mig.Name, err)
+	}
+	return nil
+}
+
+// conner is implemented by a DB which can reserve a connection, such as the
+// DB returned by db.Open which embeds *sql.DB.
+type conner interface {
+	Conn(ctx context.Context) (*sql.Conn, error)
+}
+
+// lockKey returns the key of the migration lock for the table.
+func (m *Migrator) lockKey() int64 {
+	h := fnv.New64a()
+	h.Write([]byte("sqlkit/migrate:" + m.table))
+	return int64(h.Sum64())
+}
+
+// lock takes the MySQL migration lock before the transaction starts and
+// returns a function releasing it. The lock belongs to a session so it is
+// taken and released on a reserved connection. Other dialects lock inside the
+// transaction, see lockTX.
+func (m *Migrator) lock(ctx context.Context) (func() error, error) {
+	if db.DialectOf(m.db) != db.MySQL {
+		return func() error { return nil }, nil
+	}
+	c, ok := m.db.(conner)
+	if !ok {
+		return nil, fmt.Errorf("%w: the database cannot reserve a connection", ErrLockFailed)
+	}
+	conn, err := c.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	name := fmt.Sprintf("sqlkit_migrate_%x", uint64(m.lockKey()))
+	var locked sql.NullInt64
+	err = conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, -1)", name).Scan(&locked)
+	if err == nil && locked.Int64 != 1 {
+		err = ErrLockFailed
+	}
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return func() error {
+		// Released even if ctx is done, the lock outlives the connection
+		// being returned to the pool.
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", name)
+		if cerr := conn.Close(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// lockTX takes the migration lock inside the transaction on dialects where it
+// is released when the transaction ends.
+func (m *Migrator) lockTX(ctx context.Context) error {
+	switch db.DialectOf(m.db) {
+	case db.Postgres:
+		return m.db.Exec(ctx, db.RawWithValues("SELECT pg_advisory_xact_lock($1)", m.lockKey())).Err()
+	case db.SQLite:
+		return m.db.Exec(ctx, m.db.Update(m.table).
+			Value("version", db.Raw("version")).
+			Where("version < ?", 0)).Err()
+	}
+	return nil
+}
+
+// createTable returns the statement creating the migrations table.
+func (m *Migrator) createTable() db.SQL {
+	return m.db.CreateTable(m.table).
+		IfNotExists().
+		Column("version", db.BigInt, db.PrimaryKey()).
+		Column("name", db.Varchar(255), db.NotNull()).
+		Column("applied_at", db.Timestamp, db.NotNull())
+}
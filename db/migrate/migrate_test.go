@@ -0,0 +1,191 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/colinjfw/sqlkit/db"
+	sqlite3 "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func open(t *testing.T, name string) db.DB {
+	d, err := db.Open("sqlite3", "file:"+name+"?mode=memory&cache=shared")
+	require.NoError(t, err)
+	return d
+}
+
+func tables(t *testing.T, d db.DB) []string {
+	var names []string
+	err := d.Query(context.Background(), d.Select("name").
+		From("sqlite_master").
+		Where("type = ? AND name NOT LIKE ?", "table", "sqlite_%").
+		OrderBy("name")).Decode(&names)
+	require.NoError(t, err)
+	return names
+}
+
+var migrations = []Migration{
+	SQL(2, "create_groups", "create table groups (id integer primary key)", "drop table groups"),
+	SQL(1, "create_users", "create table users (id integer primary key)", "drop table users"),
+	{
+		Version: 3,
+		Name:    "seed_users",
+		Up: func(ctx context.Context, d db.DB) error {
+			return d.Exec(ctx, d.Insert().Into("users").Columns("id").Values(1)).Err()
+		},
+	},
+}
+
+func TestMigrate_UpDown(t *testing.T) {
+	ctx := context.Background()
+	d := open(t, "updown")
+	defer d.Close()
+
+	m, err := New(d, migrations)
+	require.NoError(t, err)
+
+	require.NoError(t, m.UpTo(ctx, 2))
+	require.Equal(t, []string{"groups", "schema_migrations", "users"}, tables(t, d))
+
+	require.NoError(t, m.Up(ctx))
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.Len(t, status, 3)
+	for i, s := range status {
+		require.Equal(t, int64(i+1), s.Version)
+		require.True(t, s.Applied)
+		require.False(t, s.AppliedAt.IsZero())
+	}
+
+	err = m.Down(ctx, 0)
+	require.True(t, errors.Is(err, ErrIrreversible))
+
+	m, err = New(d, migrations[:2])
+	require.NoError(t, err)
+	err = m.Down(ctx, 1)
+	require.True(t, errors.Is(err, ErrUnknownVersion))
+
+	require.NoError(t, d.Exec(ctx, d.Delete().From("schema_migrations").Where("version = ?", 3)).Err())
+	require.NoError(t, m.Down(ctx, 1))
+	require.Equal(t, []string{"schema_migrations", "users"}, tables(t, d))
+
+	status, err = m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status[0].Applied)
+	require.False(t, status[1].Applied)
+}
+
+func TestMigrate_Failure(t *testing.T) {
+	ctx := context.Background()
+	d := open(t, "failure")
+	defer d.Close()
+
+	m, err := New(d, []Migration{
+		SQL(1, "create_users", "create table users (id integer primary key)", ""),
+		SQL(2, "broken", "create table", ""),
+	})
+	require.NoError(t, err)
+	require.Error(t, m.Up(ctx))
+
+	status, err := m.Status(ctx)
+	require.NoError(t, err)
+	require.True(t, status[0].Applied)
+	require.False(t, status[1].Applied)
+}
+
+func TestMigrate_Concurrent(t *testing.T) {
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	var count int
+	var mu sync.Mutex
+	mig := Migration{
+		Version: 1,
+		Name:    "count",
+		Up: func(ctx context.Context, d db.DB) error {
+			mu.Lock()
+			count++
+			mu.Unlock()
+			return nil
+		},
+	}
+	d := open(t, "concurrent")
+	defer d.Close()
+	m, err := New(d, []Migration{mig})
+	require.NoError(t, err)
+	require.NoError(t, d.Exec(ctx, m.createTable()).Err())
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, m.Up(ctx))
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, count)
+}
+
+func TestMigrate_Duplicate(t *testing.T) {
+	_, err := New(nil, []Migration{{Version: 1}, {Version: 1}})
+	require.True(t, errors.Is(err, ErrDuplicateVersion))
+}
+
+// lockEvents records the MySQL lock functions registered on the sqlite3_locks
+// driver along with the transactions committed.
+var (
+	lockEvents []string
+	lockMu     sync.Mutex
+)
+
+func lockEvent(e string) {
+	lockMu.Lock()
+	defer lockMu.Unlock()
+	lockEvents = append(lockEvents, e)
+}
+
+func init() {
+	sql.Register("sqlite3_locks", &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			err := conn.RegisterFunc("GET_LOCK", func(name string, timeout int64) int64 {
+				lockEvent("GET_LOCK")
+				return 1
+			}, false)
+			if err != nil {
+				return err
+			}
+			return conn.RegisterFunc("RELEASE_LOCK", func(name string) int64 {
+				lockEvent("RELEASE_LOCK")
+				return 1
+			}, false)
+		},
+	})
+}
+
+func TestMigrate_MySQLLock(t *testing.T) {
+	ctx := context.Background()
+	conn, err := sql.Open("sqlite3_locks", "file:mysqllock?mode=memory&cache=shared")
+	require.NoError(t, err)
+	defer conn.Close()
+	d := db.New(db.WithConn(conn), db.WithDialect(db.MySQL), db.WithLogger(func(s db.SQL) {
+		if sql, _, _ := s.SQL(); sql == "COMMIT" || sql == "ROLLBACK" {
+			lockEvent(sql)
+		}
+	}))
+
+	m, err := New(d, migrations[:2])
+	require.NoError(t, err)
+	require.NoError(t, m.Up(ctx))
+	require.Equal(t, []string{
+		"GET_LOCK", "COMMIT", "RELEASE_LOCK",
+		"GET_LOCK", "COMMIT", "RELEASE_LOCK",
+	}, lockEvents)
+}
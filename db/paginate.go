@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// Page describes a page of results returned by Paginate. Pages are numbered
+// from 1.
+type Page struct {
+	Page    int   // Current page.
+	PerPage int   // Rows per page.
+	Total   int64 // Total rows matching the query.
+	Pages   int   // Total number of pages.
+	HasNext bool  // Whether there is a page after the current page.
+	HasPrev bool  // Whether there is a page before the current page.
+}
+
+// PaginateOption configures Paginate.
+type PaginateOption func(*paginateConfig)
+
+type paginateConfig struct {
+	readTx bool
+}
+
+// WithReadTx runs the count and page queries in a single read only
+// transaction so that the total is consistent with the returned rows. If the
+// context is already a transaction then a savepoint is used.
+func WithReadTx() PaginateOption {
+	return func(c *paginateConfig) { c.readTx = true }
+}
+
+// Paginate decodes a page of results from q into dest and returns the page
+// metadata. The total is counted by wrapping q as a subquery without its ORDER
+// BY, LIMIT and OFFSET clauses. Pages before the first are treated as the
+// first page.
+func Paginate(ctx context.Context, d DB, q SelectStmt, page, perPage int, dest interface{}, opts ...PaginateOption) (Page, error) {
+	var conf paginateConfig
+	for _, o := range opts {
+		o(&conf)
+	}
+	if perPage < 1 {
+		return Page{}, ErrStatementInvalid
+	}
+	if page < 1 {
+		page = 1
+	}
+
+	out := Page{Page: page, PerPage: perPage}
+	run := func(ctx context.Context) error {
+		if err := d.Query(ctx, countQuery(q)).Decode(&out.Total); err != nil {
+			return err
+		}
+		sel := q.Limit(perPage).Offset((page - 1) * perPage)
+		return d.Query(ctx, sel).Decode(dest)
+	}
+
+	var err error
+	if conf.readTx {
+		txCtx := ctx
+		if _, ok := ctx.(TX); !ok {
+			txCtx = WithTxOptions(ctx, &sql.TxOptions{ReadOnly: true})
+		}
+		err = d.TX(txCtx, run)
+	} else {
+		err = run(ctx)
+	}
+	if err != nil {
+		return Page{}, err
+	}
+
+	out.Pages = int((out.Total + int64(perPage) - 1) / int64(perPage))
+	out.HasNext = page < out.Pages
+	out.HasPrev = page > 1
+	return out, nil
+}
+
+// countQuery returns a query counting the rows returned by q.
+func countQuery(q SelectStmt) SelectStmt {
+	count := SelectStmt{
+		dialect: q.dialect,
+		quote:   q.quote,
+		with:    q.with,
+		columns: []string{"COUNT(*)"},
+	}
+	q = q.Without(ClauseWith, ClauseOrderBy, ClauseLimit, ClauseOffset)
+	// The keyset condition depends on the ORDER BY and only selects a page, so
+	// it is removed to count every row.
+	q.keyset = nil
+	return count.FromSelect(q, "count_query")
+}
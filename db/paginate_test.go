@@ -0,0 +1,56 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate_CountQuery(t *testing.T) {
+	testSQL(t,
+		"WITH a AS (SELECT 1) SELECT COUNT(*) FROM (SELECT * FROM users WHERE id > $1) AS count_query",
+		[]interface{}{1},
+		countQuery(SelectStmt{dialect: Postgres}.
+			With("a", Raw("SELECT 1")).
+			Select("*").
+			From("users").
+			Where("id > ?", 1).
+			OrderBy("id").
+			Limit(10).
+			Offset(20)),
+	)
+}
+
+func TestPaginate(t *testing.T) {
+	type user struct {
+		ID int `db:"id"`
+	}
+	wrap(t, func(db DB) {
+		ctx := context.Background()
+		for i := 1; i <= 5; i++ {
+			require.NoError(t, db.Exec(ctx, db.Insert().Into("users").Columns("id").Values(i)).Err())
+		}
+		q := db.Select("*").From("users").Where("id > ?", 0).OrderBy("id")
+
+		var users []user
+		page, err := Paginate(ctx, db, q, 2, 2, &users)
+		require.NoError(t, err)
+		require.Equal(t, []user{{3}, {4}}, users)
+		require.Equal(t, Page{Page: 2, PerPage: 2, Total: 5, Pages: 3, HasNext: true, HasPrev: true}, page)
+
+		users = nil
+		page, err = Paginate(ctx, db, q, 3, 2, &users, WithReadTx())
+		require.NoError(t, err)
+		require.Equal(t, []user{{5}}, users)
+		require.Equal(t, Page{Page: 3, PerPage: 2, Total: 5, Pages: 3, HasPrev: true}, page)
+
+		_, err = Paginate(ctx, db, q, 1, 0, &users)
+		require.Equal(t, ErrStatementInvalid, err)
+	})
+}
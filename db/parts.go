@@ -0,0 +1,178 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strconv"
+
+// Parts is a read only view of the clauses configured on a statement. It can
+// be used by helpers which wrap statements, such as counting, caching or
+// auditing. Slices are copies and can be modified freely.
+type Parts struct {
+	Table   string   // Table name, or the alias of a derived table.
+	Columns []string // Selected, inserted or updated columns.
+	Where   SQL      // WHERE clause, nil if there are no conditions.
+	OrderBy []string // ORDER BY expressions.
+	Limit   int      // LIMIT, -1 if not configured.
+	Offset  int      // OFFSET, -1 if not configured.
+}
+
+// Clause identifies a clause removed by Without.
+type Clause int
+
+// Clauses which can be removed from statements. Clauses which do not apply to
+// a statement are ignored.
+const (
+	ClauseWith Clause = iota
+	ClauseDistinct
+	ClauseFrom
+	ClauseUsing
+	ClauseJoin
+	ClauseWhere
+	ClauseGroupBy
+	ClauseHaving
+	ClauseWindow
+	ClauseCompound
+	ClauseOrderBy
+	ClauseLimit
+	ClauseOffset
+	ClauseLock
+	ClauseReturning
+)
+
+// Parts returns the clauses configured on the select.
+func (q SelectStmt) Parts() Parts {
+	return Parts{
+		Table:   q.table,
+		Columns: append([]string(nil), q.columns...),
+		Where:   q.whereClause.clause(),
+		OrderBy: append([]string(nil), q.orderBy...),
+		Limit:   parseCount(q.limit),
+		Offset:  parseCount(q.offset),
+	}
+}
+
+// Without returns the select with the clauses removed. Without the FROM clause
+// the select is rendered without a table, which is invalid if it has joins.
+func (q SelectStmt) Without(clauses ...Clause) SelectStmt {
+	for _, c := range clauses {
+		switch c {
+		case ClauseWith:
+			q.with = nil
+		case ClauseDistinct:
+			q.distinct = false
+			q.distinctOn = nil
+		case ClauseFrom:
+			q.table, q.tableSel = "", nil
+		case ClauseJoin:
+			q.join = nil
+		case ClauseWhere:
+			q.whereClause = where{}
+			q.where, q.values = "", nil
+		case ClauseGroupBy:
+			q.groupBy = nil
+		case ClauseHaving:
+			q.having = where{}
+		case ClauseWindow:
+			q.windows = nil
+		case ClauseCompound:
+			q.compound = nil
+		case ClauseOrderBy:
+			q.orderBy = nil
+		case ClauseLimit:
+			q.limit = ""
+		case ClauseOffset:
+			q.offset = ""
+		case ClauseLock:
+			q.lock, q.lockOf, q.lockWait = "", nil, ""
+		}
+	}
+	return q
+}
+
+// Parts returns the clauses configured on the insert.
+func (i InsertStmt) Parts() Parts {
+	return Parts{
+		Table:   i.table,
+		Columns: append([]string(nil), i.columns...),
+		Limit:   -1,
+		Offset:  -1,
+	}
+}
+
+// Without returns the insert with the clauses removed.
+func (i InsertStmt) Without(clauses ...Clause) InsertStmt {
+	for _, c := range clauses {
+		switch c {
+		case ClauseWith:
+			i.with = nil
+		case ClauseReturning:
+			i.returning = nil
+		}
+	}
+	return i
+}
+
+// Parts returns the clauses configured on the update.
+func (i UpdateStmt) Parts() Parts {
+	return Parts{
+		Table:   i.table,
+		Columns: append([]string(nil), i.columns...),
+		Where:   i.sel.whereClause.clause(),
+		OrderBy: append([]string(nil), i.sel.orderBy...),
+		Limit:   parseCount(i.sel.limit),
+		Offset:  -1,
+	}
+}
+
+// Without returns the update with the clauses removed.
+func (i UpdateStmt) Without(clauses ...Clause) UpdateStmt {
+	for _, c := range clauses {
+		if c == ClauseFrom {
+			i.sel.table, i.sel.tableSel = "", nil
+		}
+	}
+	i.sel = i.sel.Without(clauses...)
+	return i
+}
+
+// Parts returns the clauses configured on the delete.
+func (q DeleteStmt) Parts() Parts {
+	return Parts{
+		Table:   q.sel.table,
+		Where:   q.sel.whereClause.clause(),
+		OrderBy: append([]string(nil), q.sel.orderBy...),
+		Limit:   parseCount(q.sel.limit),
+		Offset:  -1,
+	}
+}
+
+// Without returns the delete with the clauses removed.
+func (q DeleteStmt) Without(clauses ...Clause) DeleteStmt {
+	for _, c := range clauses {
+		if c == ClauseUsing {
+			q.using = nil
+		}
+	}
+	q.sel = q.sel.Without(clauses...)
+	return q
+}
+
+// clause returns the where clause as SQL, or nil if it is empty.
+func (q where) clause() SQL {
+	if q.sql == nil {
+		return nil
+	}
+	return q
+}
+
+// parseCount parses a LIMIT or OFFSET, returning -1 if it is not configured.
+func parseCount(s string) int {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return -1
+	}
+	return n
+}
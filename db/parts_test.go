@@ -0,0 +1,103 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParts_Select(t *testing.T) {
+	q := Select("id", "name").From("users").Where("id > ?", 1).OrderBy("id").Limit(10)
+	p := q.Parts()
+	require.Equal(t, "users", p.Table)
+	require.Equal(t, []string{"id", "name"}, p.Columns)
+	require.Equal(t, []string{"id"}, p.OrderBy)
+	require.Equal(t, 10, p.Limit)
+	require.Equal(t, -1, p.Offset)
+	sql, args, err := p.Where.SQL()
+	require.NoError(t, err)
+	require.Equal(t, "id > ?", sql)
+	require.Equal(t, []interface{}{1}, args)
+
+	p.Columns[0] = "changed"
+	require.Equal(t, []string{"id", "name"}, q.Parts().Columns)
+	require.Nil(t, Select("*").Parts().Where)
+}
+
+func TestParts_Update(t *testing.T) {
+	p := Update("users").Value("name", "a").Where("id = ?", 1).Parts()
+	require.Equal(t, "users", p.Table)
+	require.Equal(t, []string{"name"}, p.Columns)
+	require.NotNil(t, p.Where)
+}
+
+func TestParts_Without(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users",
+		nil,
+		Select("*").
+			From("users").
+			Join("groups", "groups.id = users.group_id").
+			Where("id > ?", 1).
+			OrderBy("id").
+			Limit(10).
+			Offset(5).
+			Without(ClauseJoin, ClauseWhere, ClauseOrderBy, ClauseLimit, ClauseOffset),
+	)
+	testSQL(t,
+		"SELECT 1",
+		nil,
+		Select("1").From("users").Where("id > ?", 1).Without(ClauseFrom, ClauseWhere),
+	)
+	_, _, err := Select("1").From("users").Join("groups", "groups.id = users.group_id").Without(ClauseFrom).SQL()
+	require.Equal(t, ErrStatementInvalid, err)
+	testSQL(t,
+		"DELETE FROM users WHERE id = ?",
+		[]interface{}{1},
+		Delete().From("users").Using("groups").Where("id = ?", 1).Without(ClauseUsing),
+	)
+	testSQL(t,
+		"UPDATE users SET name=? WHERE id = ?",
+		[]interface{}{"a", 1},
+		Update("users").From("groups").Value("name", "a").Where("id = ?", 1).Without(ClauseFrom),
+	)
+}
+
+func TestParts_NoCallerAliasing(t *testing.T) {
+	cols := []string{"n"}
+	vals := []interface{}{1}
+	q := Insert().Into("users").Row([]string{"id"}, vals).
+		WithRecursive("t", cols, Raw("SELECT 1"))
+	vals[0] = 2
+	cols[0] = "m"
+	testSQL(t, "WITH RECURSIVE t (n) AS (SELECT 1) INSERT INTO users (id) VALUES (?)", []interface{}{1}, q)
+
+	s := Select("*").From("t").WithRecursive("t", cols, Raw("SELECT 1"))
+	cols[0] = "x"
+	testSQL(t, "WITH RECURSIVE t (m) AS (SELECT 1) SELECT * FROM t", nil, s)
+}
+
+func TestParts_NoAliasing(t *testing.T) {
+	base := Select("*").From("users").Join("a", "a.id = users.a_id")
+	base = base.Join("b", "b.id = users.b_id")
+	q1 := base.Join("c", "c.id = users.c_id")
+	q2 := base.Join("d", "d.id = users.d_id")
+
+	sql, _, err := q1.SQL()
+	require.NoError(t, err)
+	require.Contains(t, sql, " c ON ")
+	sql, _, err = q2.SQL()
+	require.NoError(t, err)
+	require.Contains(t, sql, " d ON ")
+	require.NotContains(t, sql, " c ON ")
+
+	cols := []string{"id", "name"}
+	q := Select(cols...)
+	cols[0] = "changed"
+	require.Equal(t, []string{"id", "name"}, q.Parts().Columns)
+}
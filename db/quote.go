@@ -0,0 +1,128 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strings"
+
+// quoteIdent quotes the identifiers in a table, column or ORDER BY expression.
+// It accepts a dotted path such as schema.table or table.*, followed by an
+// optional alias with or without AS, a sort direction and NULLS FIRST or LAST.
+// Anything else is treated as a raw expression and returned unchanged.
+func quoteIdent(expr, quote string) string {
+	fields := strings.Fields(expr)
+	if len(fields) == 0 {
+		return expr
+	}
+	path, ok := quotePath(fields[0], quote)
+	if !ok {
+		return expr
+	}
+	out := []string{path}
+	rest := fields[1:]
+
+	// Optional alias.
+	if len(rest) > 1 && strings.EqualFold(rest[0], "AS") && isIdent(rest[1]) {
+		out = append(out, "AS", quote+rest[1]+quote)
+		rest = rest[2:]
+	} else if len(rest) > 0 && isIdent(rest[0]) && !isSortKeyword(rest[0]) {
+		out = append(out, quote+rest[0]+quote)
+		rest = rest[1:]
+	}
+	// Optional sort direction and null ordering.
+	if len(rest) > 0 && (strings.EqualFold(rest[0], "ASC") || strings.EqualFold(rest[0], "DESC")) {
+		out = append(out, rest[0])
+		rest = rest[1:]
+	}
+	if len(rest) == 2 && strings.EqualFold(rest[0], "NULLS") &&
+		(strings.EqualFold(rest[1], "FIRST") || strings.EqualFold(rest[1], "LAST")) {
+		out = append(out, rest...)
+		rest = nil
+	}
+	if len(rest) > 0 {
+		return expr
+	}
+	return strings.Join(out, " ")
+}
+
+// quotePath quotes each part of a dotted identifier path. The last part may be
+// a '*' which is left unquoted. Keywords such as NULL are not identifiers.
+func quotePath(path, quote string) (string, bool) {
+	if path == "*" {
+		return path, true
+	}
+	if isKeyword(path) {
+		return "", false
+	}
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if part == "*" && i == len(parts)-1 && i > 0 {
+			continue
+		}
+		if !isIdent(part) {
+			return "", false
+		}
+		parts[i] = quote + part + quote
+	}
+	return strings.Join(parts, "."), true
+}
+
+// isIdent reports whether s is a plain unquoted identifier.
+func isIdent(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, c := range s {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case i > 0 && (c == '$' || c >= '0' && c <= '9'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// isKeyword reports whether s is a keyword which is valid where a column name
+// is expected, such as a literal or a function called without parentheses.
+func isKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "NULL", "TRUE", "FALSE", "DEFAULT", "DISTINCT", "ALL",
+		"CURRENT_DATE", "CURRENT_TIME", "CURRENT_TIMESTAMP", "CURRENT_USER",
+		"LOCALTIME", "LOCALTIMESTAMP", "SESSION_USER":
+		return true
+	}
+	return false
+}
+
+func isSortKeyword(s string) bool {
+	switch strings.ToUpper(s) {
+	case "ASC", "DESC", "NULLS":
+		return true
+	}
+	return false
+}
+
+func (m genericMapper) identifier(name string) string {
+	return quoteIdent(name, m.quote)
+}
+
+// ident writes an identifier, quoting it if quoting is enabled.
+func (m genericMapper) ident(b *builder, name string) {
+	if b.quote {
+		name = quoteIdent(name, m.quote)
+	}
+	b.WriteString(name)
+}
+
+// idents writes a list of identifiers separated by sep.
+func (m genericMapper) idents(b *builder, names []string, sep string) {
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(sep)
+		}
+		m.ident(b, name)
+	}
+}
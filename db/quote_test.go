@@ -0,0 +1,120 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuote_Ident(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"order", `"order"`},
+		{"public.users", `"public"."users"`},
+		{"users.*", `"users".*`},
+		{"*", `*`},
+		{"users u", `"users" "u"`},
+		{"users AS u", `"users" AS "u"`},
+		{"createdAt DESC", `"createdAt" DESC`},
+		{"id asc NULLS LAST", `"id" asc NULLS LAST`},
+		{"count(*)", `count(*)`},
+		{"id + 1", `id + 1`},
+		{`"user"`, `"user"`},
+		{"1", `1`},
+		{"NULL", `NULL`},
+		{"true", `true`},
+		{"CURRENT_TIMESTAMP", `CURRENT_TIMESTAMP`},
+		{"DEFAULT", `DEFAULT`},
+		{"NULL AS missing", `NULL AS missing`},
+		{"DISTINCT id", `DISTINCT id`},
+	} {
+		require.Equal(t, tc.out, quoteIdent(tc.in, `"`), tc.in)
+	}
+}
+
+func TestQuote_SQLPostgres(t *testing.T) {
+	d := New(WithDialect(Postgres), WithIdentifierQuoting())
+	testSQL(t,
+		`SELECT "user"."id","Name",count(*) FROM "public"."user" `+
+			`INNER JOIN "order" ON "order".user_id = "user".id WHERE id = $1 `+
+			`GROUP BY "user"."id" ORDER BY "Name" DESC`,
+		[]interface{}{1},
+		d.Select("user.id", "Name", "count(*)").
+			From("public.user").
+			InnerJoin("order", `"order".user_id = "user".id`).
+			Where("id = ?", 1).
+			GroupBy("user.id").
+			OrderBy("Name DESC"),
+	)
+	testSQL(t,
+		`INSERT INTO "user" ("id", "order") VALUES ($1, $2) RETURNING "id"`,
+		[]interface{}{1, 2},
+		d.Insert().Into("user").Columns("id", "order").Values(1, 2).Returning("id"),
+	)
+	testSQL(t,
+		`UPDATE "user" SET "order"=$1 WHERE id = $2`,
+		[]interface{}{2, 1},
+		d.Update("user").Value("order", 2).Where("id = ?", 1),
+	)
+	testSQL(t,
+		`DELETE FROM "user" WHERE id = $1`,
+		[]interface{}{1},
+		d.Delete().From("user").Where("id = ?", 1),
+	)
+}
+
+func TestQuote_SQLPredicates(t *testing.T) {
+	d := New(WithDialect(Postgres), WithIdentifierQuoting())
+	testSQL(t,
+		`SELECT * FROM "order" WHERE ((("order"."group" = $1) AND ("user" IN ($2, $3))) `+
+			`AND (lower(name) LIKE $4)) HAVING ("count" > $5)`,
+		[]interface{}{1, 2, 3, "a%", 4},
+		d.Select("*").
+			From("order").
+			Where(Eq("order.group", 1).And(In("user", []int{2, 3}))).
+			Where(Like("lower(name)", "a%")).
+			Having(Gt("count", 4)),
+	)
+	testSQL(t,
+		`UPDATE "order" SET "group"=$1 WHERE ("user" = $2)`,
+		[]interface{}{1, 2},
+		d.Update("order").Value("group", 1).Where(Eq("user", 2)),
+	)
+
+	cursor, err := encodeCursor([]interface{}{int64(5)})
+	require.Nil(t, err)
+	testSQL(t,
+		`SELECT * FROM "order" WHERE ("user" > $1) ORDER BY "user"`,
+		[]interface{}{int64(5)},
+		d.Select("*").From("order").OrderBy("user").After(cursor),
+	)
+
+	testSQL(t,
+		`SELECT * FROM order WHERE (user = $1)`,
+		[]interface{}{2},
+		New(WithDialect(Postgres)).Select("*").From("order").Where(Eq("user", 2)),
+	)
+}
+
+func TestQuote_SQLMySQL(t *testing.T) {
+	d := New(WithDialect(MySQL), WithIdentifierQuoting())
+	testSQL(t,
+		"SELECT `id` FROM `order` ORDER BY `id`",
+		nil,
+		d.Select("id").From("order").OrderBy("id"),
+	)
+}
+
+func TestQuote_Disabled(t *testing.T) {
+	testSQL(t,
+		"SELECT id FROM users ORDER BY id",
+		nil,
+		New().Select("id").From("users").OrderBy("id"),
+	)
+}
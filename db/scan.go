@@ -0,0 +1,143 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strings"
+
+// Kinds of tokens found when scanning a query.
+const (
+	tokenText        int = iota
+	tokenPlaceholder     // A '?' placeholder.
+	tokenEscaped         // A '??' written as a literal '?'.
+	tokenNumbered        // A '$n' placeholder.
+	tokenString          // A quoted or dollar quoted string literal.
+	tokenQuoted          // A quoted identifier.
+	tokenComment         // A line or block comment.
+)
+
+type token struct {
+	kind int
+	text string
+}
+
+// scanSQL splits a query into tokens. String literals, quoted identifiers and
+// comments are returned as single tokens, so placeholders inside them are not
+// found. The Postgres JSONB operators '?|' and '?&' are treated as text. If
+// backslash is set a backslash escapes the next character in quoted strings,
+// as on MySQL. Backslashes always escape in Postgres E'...' strings.
+func scanSQL(query string, backslash bool) []token {
+	var tokens []token
+	start := 0
+	for i := 0; i < len(query); {
+		kind, end := scanToken(query, i, backslash)
+		if kind != tokenText {
+			if i > start {
+				tokens = append(tokens, token{tokenText, query[start:i]})
+			}
+			tokens = append(tokens, token{kind, query[i:end]})
+			start = end
+		}
+		i = end
+	}
+	if start < len(query) {
+		tokens = append(tokens, token{tokenText, query[start:]})
+	}
+	return tokens
+}
+
+// scanToken returns the kind of the token starting at i and the index after
+// it. Text is returned one character at a time, except for the operators
+// '?|' and '?&'. See scanSQL for backslash.
+func scanToken(query string, i int, backslash bool) (int, int) {
+	c := query[i]
+	switch {
+	case c == '\'':
+		return tokenString, skipQuoted(query, i, backslash)
+	case c == '"':
+		return tokenQuoted, skipQuoted(query, i, backslash)
+	case c == '`':
+		return tokenQuoted, skipQuoted(query, i, false)
+	case (c == 'E' || c == 'e') && strings.HasPrefix(query[i+1:], "'") &&
+		(i == 0 || !isIdentByte(query[i-1])):
+		return tokenString, skipQuoted(query, i+1, true)
+	case strings.HasPrefix(query[i:], "--"):
+		if n := strings.IndexByte(query[i:], '\n'); n >= 0 {
+			return tokenComment, i + n + 1
+		}
+		return tokenComment, len(query)
+	case strings.HasPrefix(query[i:], "/*"):
+		if n := strings.Index(query[i+2:], "*/"); n >= 0 {
+			return tokenComment, i + n + 4
+		}
+		return tokenComment, len(query)
+	case c == '?':
+		switch {
+		case strings.HasPrefix(query[i:], "??"):
+			return tokenEscaped, i + 2
+		case strings.HasPrefix(query[i:], "?&"),
+			strings.HasPrefix(query[i:], "?|") && !strings.HasPrefix(query[i:], "?||"):
+			return tokenText, i + 2
+		}
+		return tokenPlaceholder, i + 1
+	case c == '$' && (i == 0 || !isIdentByte(query[i-1])):
+		end := i + 1
+		for end < len(query) && query[end] >= '0' && query[end] <= '9' {
+			end++
+		}
+		if end > i+1 {
+			return tokenNumbered, end
+		}
+		if end = skipDollarQuoted(query, i); end > i+1 {
+			return tokenString, end
+		}
+	}
+	return tokenText, i + 1
+}
+
+// skipQuoted returns the index after the quoted string or identifier starting
+// at i. A doubled quote character is treated as an escaped quote, as is a
+// quote following a backslash if backslash is set.
+func skipQuoted(query string, i int, backslash bool) int {
+	quote := query[i]
+	for i++; i < len(query); i++ {
+		if backslash && query[i] == '\\' {
+			i++
+			continue
+		}
+		if query[i] != quote {
+			continue
+		}
+		if i+1 < len(query) && query[i+1] == quote {
+			i++
+			continue
+		}
+		return i + 1
+	}
+	return len(query)
+}
+
+// skipDollarQuoted returns the index after a dollar quoted string such as
+// $$...$$ or $tag$...$tag$ starting at i. If there is no dollar quote at i the
+// next index is returned.
+func skipDollarQuoted(query string, i int) int {
+	end := i + 1
+	for end < len(query) && isIdentByte(query[end]) && query[end] != '$' {
+		end++
+	}
+	if end >= len(query) || query[end] != '$' {
+		return i + 1
+	}
+	tag := query[i : end+1]
+	if close := strings.Index(query[end+1:], tag); close >= 0 {
+		return end + 1 + close + len(tag)
+	}
+	return len(query)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= '0' && c <= '9' ||
+		c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,106 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestScan_Rebind(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{"a = ? AND b = ?", "a = $1 AND b = $2"},
+		{"?", "$1"},
+		{"a = '?' AND b = ?", "a = '?' AND b = $1"},
+		{"a = 'it''s ?' AND b = ?", "a = 'it''s ?' AND b = $1"},
+		{`"col?" = ?`, `"col?" = $1`},
+		{"`col?` = ?", "`col?` = $1"},
+		{"a = ? -- why?\nAND b = ?", "a = $1 -- why?\nAND b = $2"},
+		{"a = ? /* why? */ AND b = ?", "a = $1 /* why? */ AND b = $2"},
+		{"data ?| ? AND data ?& ?", "data ?| $1 AND data ?& $2"},
+		{"data ?? 'key' AND b = ?", "data ? 'key' AND b = $1"},
+		{"? || 'x'", "$1 || 'x'"},
+		{"?||'x'", "$1||'x'"},
+		{"$$ a = ? $$ = ?", "$$ a = ? $$ = $1"},
+		{"$fn$ a = ? $fn$ = ?", "$fn$ a = ? $fn$ = $1"},
+		{"a$b = ?", "a$b = $1"},
+		{"'unterminated ?", "'unterminated ?"},
+		{`a = E'\'?' AND b = ?`, `a = E'\'?' AND b = $1`},
+		{`a = e'\\' AND b = ?`, `a = e'\\' AND b = $1`},
+		{`a = 'x\' AND b = ?`, `a = 'x\' AND b = $1`},
+		{`name = ?`, `name = $1`},
+	} {
+		require.Equal(t, tc.out, rebind(bindDollar, tc.in, false), tc.in)
+	}
+}
+
+func TestScan_RebindQuestion(t *testing.T) {
+	require.Equal(t, "a = ? AND b ? 'k'", rebind(bindQuestion, "a = ? AND b ?? 'k'", false))
+}
+
+func TestScan_Backslash(t *testing.T) {
+	for _, tc := range []struct {
+		in, out string
+	}{
+		{`a = 'it\'s ?' AND b = ?`, `a = 'it\'s ?' AND b = $1`},
+		{`a = "say \"?\"" AND b = ?`, `a = "say \"?\"" AND b = $1`},
+		{`a = '\\' AND b = ?`, `a = '\\' AND b = $1`},
+		{"`a\\` = ?", "`a\\` = $1"},
+	} {
+		require.Equal(t, tc.out, rebind(bindDollar, tc.in, true), tc.in)
+	}
+
+	sql, values, err := SelectStmt{dialect: MySQL}.
+		Select("*").
+		From("users").
+		Where(`name != 'it\'s ?' AND id IN ?`, []int{1, 2}).
+		SQL()
+	require.NoError(t, err)
+	require.Equal(t, `SELECT * FROM users WHERE name != 'it\'s ?' AND id IN (?, ?)`, sql)
+	require.Equal(t, []interface{}{1, 2}, values)
+}
+
+func TestScan_Numbered(t *testing.T) {
+	var kinds []int
+	for _, tok := range scanSQL("a = $1 AND b = $12 AND c = '$3'", false) {
+		kinds = append(kinds, tok.kind)
+	}
+	require.Equal(t, []int{
+		tokenText, tokenNumbered, tokenText, tokenNumbered, tokenText, tokenString,
+	}, kinds)
+}
+
+func TestScan_Kinds(t *testing.T) {
+	var texts []string
+	var kinds []int
+	for _, tok := range scanSQL("SELECT \"a?\", $$?$$, E'\\'?' /* ? */ FROM t -- ?\nWHERE b ?| c", false) {
+		texts = append(texts, tok.text)
+		kinds = append(kinds, tok.kind)
+	}
+	require.Equal(t, []string{
+		"SELECT ", `"a?"`, ", ", "$$?$$", ", ", `E'\'?'`, " ", "/* ? */", " FROM t ", "-- ?\n", "WHERE b ?| c",
+	}, texts)
+	require.Equal(t, []int{
+		tokenText, tokenQuoted, tokenText, tokenString, tokenText, tokenString, tokenText,
+		tokenComment, tokenText, tokenComment, tokenText,
+	}, kinds)
+}
+
+func TestScan_InsertQuestions(t *testing.T) {
+	sql, err := insertQuestions("? IN ?", 0, 2, false)
+	require.NoError(t, err)
+	require.Equal(t, "(?, ?) IN ?", sql)
+
+	sql, err = insertQuestions("a = '?' AND b ?? 'k' AND c IN ?", 0, 3, false)
+	require.NoError(t, err)
+	require.Equal(t, "a = '?' AND b ?? 'k' AND c IN (?, ?, ?)", sql)
+
+	_, err = insertQuestions("a = '?'", 0, 1, false)
+	require.Error(t, err)
+}
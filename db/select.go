@@ -8,66 +8,116 @@ package db
 import (
 	"fmt"
 	"strconv"
+	"strings"
+
+	"github.com/colinjfw/sqlkit/encoding"
 )
 
 // Select returns a new SelectStmt.
-func Select(cols ...string) SelectStmt { return SelectStmt{columns: cols} }
+func Select(cols ...string) SelectStmt { return SelectStmt{}.Select(cols...) }
 
 // SelectStmt represents a SELECT in sql.
+// Builder methods return a copy of the statement which never shares slices
+// with the original, so a statement can be reused as a base for others.
 type SelectStmt struct {
 	dialect     Dialect
+	quote       bool
+	with        []cte
+	distinct    bool
+	distinctOn  []string
 	columns     []string
 	table       string
+	tableSel    *SelectStmt
 	groupBy     []string
 	orderBy     []string
 	offset      string
 	limit       string
-	join        [][]string
+	lock        string
+	lockOf      []string
+	lockWait    string
+	join        []joinClause
+	compound    []compound
 	whereClause where
 	where       string
+	having      where
+	windows     []namedWindow
+	keyset      *keyset
 	values      []interface{}
 	err         error
+	encoder     encoding.Encoder
 }
 
 // Select configures the columns to select.
 func (q SelectStmt) Select(cols ...string) SelectStmt {
-	q.columns = cols
+	q.columns = append([]string(nil), cols...)
+	return q
+}
+
+// Distinct configures the select to return only distinct rows.
+func (q SelectStmt) Distinct() SelectStmt {
+	q.distinct = true
+	return q
+}
+
+// DistinctOn configures the select to return the first row for each distinct
+// set of values for the expressions. This is only supported on Postgres, other
+// dialects return an error when the statement is built.
+func (q SelectStmt) DistinctOn(cols ...string) SelectStmt {
+	q.distinctOn = append([]string(nil), cols...)
 	return q
 }
 
 // From configures the table.
 func (q SelectStmt) From(table string) SelectStmt {
 	q.table = table
+	q.tableSel = nil
+	return q
+}
+
+// FromSelect configures a derived table to select from. This renders as
+// FROM (SELECT ...) AS alias, values bound in the subquery are placed before
+// the values from joins and the WHERE clause.
+func (q SelectStmt) FromSelect(sel SelectStmt, alias string) SelectStmt {
+	q.table = alias
+	q.tableSel = &sel
 	return q
 }
 
 // insertQuestions will insert count questions in str at the question mark idx.
-// This turns 'x in ?' into 'x in (?, ?)'.
-func insertQuestions(str string, insertAt, count int) (string, error) {
+// Question marks which are not placeholders are skipped when counting, see
+// scanSQL for backslash.
+func insertQuestions(str string, insertAt, count int, backslash bool) (string, error) {
+	var out strings.Builder
 	var qIdx int
-	var sIdx int
-	for i, char := range str {
-		if char == '?' {
+	found := false
+	for _, t := range scanSQL(str, backslash) {
+		if t.kind == tokenPlaceholder {
 			if qIdx == insertAt {
-				sIdx = i
-				break
+				out.WriteString(questions(count))
+				found = true
+				qIdx++
+				continue
 			}
 			qIdx++
 		}
+		out.WriteString(t.text)
 	}
-	if sIdx == 0 {
+	if !found {
 		// Couldn't find a question mark at this index.
 		return str, fmt.Errorf(
 			"sqlkit/db: could not find matching '?' at index %d", insertAt)
 	}
-	return str[:sIdx] + questions(count) + str[sIdx+1:], nil
+	return out.String(), nil
 }
 
 // Where configures the WHERE clause. It expects values to be interpolated using
 // the question (?) mark parameter. For values that are slices, the question
 // mark will be transformed in the where query. This means that IN queries can
 // be writted without knowing the specific number of arguments needed in the
-// array.
+// array. Byte slices and slices implementing driver.Valuer are not expanded,
+// see List and Array to choose explicitly. Question marks inside quoted
+// strings and comments are not treated as placeholders, and a literal question
+// mark can be written as '??'.
 //
 // The where parameter can take multiple types.
 func (q SelectStmt) Where(where interface{}, values ...interface{}) SelectStmt {
@@ -75,15 +125,37 @@ func (q SelectStmt) Where(where interface{}, values ...interface{}) SelectStmt {
 	return q
 }
 
+// OrWhere configures the WHERE clause like Where but joins the condition to
+// the previous conditions with OR.
+func (q SelectStmt) OrWhere(where interface{}, values ...interface{}) SelectStmt {
+	q.whereClause = q.whereClause.where(or, where, values...)
+	return q
+}
+
+// WhereGroup adds the conditions configured by fn on an empty select as a
+// single parenthesized condition joined with AND. Conditions inside the group
+// can be combined using Where and OrWhere.
+func (q SelectStmt) WhereGroup(fn func(SelectStmt) SelectStmt) SelectStmt {
+	q.whereClause = q.whereClause.where(and, fn(SelectStmt{}).whereClause.group())
+	return q
+}
+
 // GroupBy configures the GROUP BY clause.
 func (q SelectStmt) GroupBy(groupBy ...string) SelectStmt {
-	q.groupBy = groupBy
+	q.groupBy = append([]string(nil), groupBy...)
+	return q
+}
+
+// Having configures the HAVING clause. It accepts the same forms as Where,
+// including expanding slice values. Multiple calls are joined with AND.
+func (q SelectStmt) Having(having interface{}, values ...interface{}) SelectStmt {
+	q.having = q.having.where(and, having, values...)
 	return q
 }
 
 // OrderBy configures the ORDER BY clause.
 func (q SelectStmt) OrderBy(orderBy ...string) SelectStmt {
-	q.orderBy = orderBy
+	q.orderBy = append([]string(nil), orderBy...)
 	return q
 }
 
@@ -99,46 +171,82 @@ func (q SelectStmt) Limit(limit int) SelectStmt {
 	return q
 }
 
+// joinClause represents a single JOIN with the values bound in the ON clause.
+// If sel is set the join is a derived table and table is its alias.
+type joinClause struct {
+	kind   string
+	table  string
+	sel    *SelectStmt
+	on     string
+	values []interface{}
+}
+
 // join adds a join statement of a specific kind.
 func (q SelectStmt) joins(kind, table, on string, values ...interface{}) SelectStmt {
-	q.join = append(q.join, []string{kind, table, on})
-	q.values = append(q.values, values...)
+	q.join = append(q.join[:len(q.join):len(q.join)], joinClause{
+		kind:   kind,
+		table:  table,
+		on:     on,
+		values: values,
+	})
 	return q
 }
 
-// Join adds a join.
-func (q SelectStmt) Join(table, on string) SelectStmt {
-	return q.joins("", table, on)
+// Join adds a join. Values are bound to the placeholders in the ON clause.
+func (q SelectStmt) Join(table, on string, values ...interface{}) SelectStmt {
+	return q.joins("", table, on, values...)
 }
 
 // InnerJoin adds a join of type INNER.
-func (q SelectStmt) InnerJoin(table, on string) SelectStmt {
-	return q.joins("INNER", table, on)
+func (q SelectStmt) InnerJoin(table, on string, values ...interface{}) SelectStmt {
+	return q.joins("INNER", table, on, values...)
 }
 
 // LeftJoin adds a join of type LEFT.
-func (q SelectStmt) LeftJoin(table, on string) SelectStmt {
-	return q.joins("LEFT", table, on)
+func (q SelectStmt) LeftJoin(table, on string, values ...interface{}) SelectStmt {
+	return q.joins("LEFT", table, on, values...)
 }
 
 // RightJoin adds a join of type RIGHT.
-func (q SelectStmt) RightJoin(table, on string) SelectStmt {
-	return q.joins("RIGHT", table, on)
+func (q SelectStmt) RightJoin(table, on string, values ...interface{}) SelectStmt {
+	return q.joins("RIGHT", table, on, values...)
+}
+
+// JoinSelect adds a join of the kind, such as "LEFT" or "INNER", to a derived
+// table. This renders as JOIN (SELECT ...) AS alias ON ..., values bound in
+// the subquery are placed before the values bound in the ON clause.
+func (q SelectStmt) JoinSelect(kind string, sel SelectStmt, alias, on string, values ...interface{}) SelectStmt {
+	q.join = append(q.join[:len(q.join):len(q.join)], joinClause{
+		kind:   kind,
+		table:  alias,
+		sel:    &sel,
+		on:     on,
+		values: values,
+	})
+	return q
 }
 
 // SQL implements the SQL interface.
 func (q SelectStmt) SQL() (string, []interface{}, error) {
-	if q.err != nil {
-		return "", nil, q.err
+	sql, values, err := q.unboundSQL()
+	if err != nil {
+		return "", nil, err
 	}
-	q = q.parseWhere()
-	sql := dialects[q.dialect].query(q)
-	return sql, q.values, q.err
+	return dialects[q.dialect].rebind(sql), argValues(values), nil
+}
+
+func (q SelectStmt) unboundSQL() (string, []interface{}, error) {
+	b := &builder{quote: q.quote}
+	dialects[q.dialect].query(b, q)
+	return b.result()
 }
 
 func (q SelectStmt) parseWhere() SelectStmt {
-	var values []interface{}
-	q.where, values, q.err = q.whereClause.SQL()
-	q.values = append(q.values, values...)
+	return q.renderWhere(dialects[q.dialect], q.quote)
+}
+
+// renderWhere renders the where clause for a dialect.
+func (q SelectStmt) renderWhere(m dialectMapper, quote bool) SelectStmt {
+	q.where, q.values, q.err = q.whereClause.render(m, quote)
 	return q
 }
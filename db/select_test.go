@@ -6,6 +6,8 @@
 package db
 
 import (
+	"database/sql/driver"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -104,6 +106,70 @@ func TestSelect_SQLInnerJoin(t *testing.T) {
 	)
 }
 
+func TestSelect_SQLJoinValues(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users LEFT JOIN groups ON users.group_id = groups.id AND groups.kind = ? WHERE users.name = ?",
+		[]interface{}{"a", "b"},
+		Select("*").
+			From("users").
+			LeftJoin("groups", "users.group_id = groups.id AND groups.kind = ?", "a").
+			Where("users.name = ?", "b"),
+	)
+}
+
+func TestSelect_SQLFromSelect(t *testing.T) {
+	d := New(WithDialect(Postgres))
+	testSQL(t,
+		"SELECT * FROM (SELECT user_id,count(*) AS n FROM orders WHERE total > $1 GROUP BY user_id) AS o "+
+			"LEFT JOIN (SELECT id FROM users WHERE active = $2) AS u ON u.id = o.user_id AND o.n > $3 "+
+			"INNER JOIN groups ON groups.id = u.group_id AND groups.kind = $4 WHERE o.n < $5",
+		[]interface{}{10, true, 1, "a", 100},
+		d.Select("*").
+			FromSelect(d.Select("user_id", "count(*) AS n").
+				From("orders").
+				Where("total > ?", 10).
+				GroupBy("user_id"), "o").
+			JoinSelect("LEFT", d.Select("id").From("users").Where("active = ?", true),
+				"u", "u.id = o.user_id AND o.n > ?", 1).
+			InnerJoin("groups", "groups.id = u.group_id AND groups.kind = ?", "a").
+			Where("o.n < ?", 100),
+	)
+}
+
+func TestSelect_SQLDistinct(t *testing.T) {
+	testSQL(t,
+		"SELECT DISTINCT name,email FROM users",
+		nil,
+		Select("name", "email").
+			From("users").
+			Distinct(),
+	)
+}
+
+func TestSelect_SQLDistinctOn(t *testing.T) {
+	testSQL(t,
+		"SELECT DISTINCT ON (user_id, kind) user_id,kind,created_at FROM events WHERE kind != $1 ORDER BY user_id, kind, created_at DESC",
+		[]interface{}{"a"},
+		SelectStmt{dialect: Postgres}.
+			Select("user_id", "kind", "created_at").
+			DistinctOn("user_id", "kind").
+			From("events").
+			Where("kind != ?", "a").
+			OrderBy("user_id", "kind", "created_at DESC"),
+	)
+}
+
+func TestSelect_DistinctOnUnsupported(t *testing.T) {
+	for _, d := range []Dialect{Generic, MySQL, SQLite} {
+		_, _, err := SelectStmt{dialect: d}.
+			Select("*").
+			DistinctOn("user_id").
+			From("events").
+			SQL()
+		require.True(t, errors.Is(err, ErrUnsupported))
+	}
+}
+
 func TestSelect_SQLPostgres(t *testing.T) {
 	testSQL(t,
 		"SELECT * FROM users WHERE name = $1",
@@ -222,3 +288,121 @@ func BenchmarkFib10(b *testing.B) {
 			SQL()
 	}
 }
+
+func TestSelect_SQLPostgresSubquery(t *testing.T) {
+	d := New(WithDialect(Postgres))
+	testSQL(t,
+		"SELECT * FROM users WHERE (name = ? AND (id IN (SELECT user_id FROM groups WHERE name = ?)))",
+		[]interface{}{"a", "b"},
+		Select("*").
+			From("users").
+			Where("name = ?", "a").
+			Where(In("id", d.Select("user_id").From("groups").Where("name = ?", "b"))),
+	)
+	testSQL(t,
+		"SELECT * FROM users WHERE (name = $1 AND (id IN (SELECT user_id FROM groups WHERE name = $2)))",
+		[]interface{}{"a", "b"},
+		d.Select("*").
+			From("users").
+			Where("name = ?", "a").
+			Where(In("id", d.Select("user_id").From("groups").Where("name = ?", "b"))),
+	)
+}
+
+func TestSelect_SQLOrWhere(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users WHERE ((a = $1 OR (b = $2)) AND (c = $3 OR d IN ($4, $5)))",
+		[]interface{}{1, 2, 3, 4, 5},
+		SelectStmt{dialect: Postgres}.
+			Select("*").
+			From("users").
+			Where("a = ?", 1).
+			OrWhere(Eq("b", 2)).
+			WhereGroup(func(q SelectStmt) SelectStmt {
+				return q.Where("c = ?", 3).OrWhere("d IN ?", []int{4, 5})
+			}),
+	)
+}
+
+func TestSelect_SQLWhereMultipleSlices(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users WHERE ((a IN (?, ?)) AND (b NOT IN (?, ?, ?)))",
+		[]interface{}{1, 2, 3, 4, 5},
+		Select("*").
+			From("users").
+			Where(In("a", []int{1, 2}).And(NotIn("b", []int{3, 4, 5}))),
+	)
+}
+
+func TestSelect_SQLWhereGroupEmpty(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users WHERE a = ?",
+		[]interface{}{1},
+		Select("*").
+			From("users").
+			Where("a = ?", 1).
+			WhereGroup(func(q SelectStmt) SelectStmt { return q }),
+	)
+}
+
+type valuerSlice []int
+
+func (v valuerSlice) Value() (driver.Value, error) { return "{1,2}", nil }
+
+func TestSelect_SliceExpansion(t *testing.T) {
+	blob := []byte("abc")
+	testSQL(t,
+		"SELECT * FROM users WHERE ((((data = ? AND tags = ?) AND id IN (?, ?)) AND x IN (?, ?)) AND y = ?)",
+		[]interface{}{blob, valuerSlice{1, 2}, 1, 2, 3, 4, []int{5, 6}},
+		Select("*").
+			From("users").
+			Where("data = ?", blob).
+			Where("tags = ?", valuerSlice{1, 2}).
+			Where("id IN ?", []int{1, 2}).
+			Where("x IN ?", List(valuerSlice{3, 4})).
+			Where("y = ?", Array([]int{5, 6})),
+	)
+}
+
+func TestSelect_ArrayOutsideWhere(t *testing.T) {
+	testSQL(t,
+		"INSERT INTO users (tags) VALUES (?)",
+		[]interface{}{[]string{"a"}},
+		Insert().Into("users").Columns("tags").Values(Array([]string{"a"})),
+	)
+}
+
+func TestSelect_ArrayInSubquery(t *testing.T) {
+	sub := Select("id").From("t").Where("tags = ?", Array([]string{"a", "b"}))
+	testSQL(t,
+		"SELECT * FROM users WHERE ((id IN (SELECT id FROM t WHERE tags = $1)) AND x IN ($2, $3))",
+		[]interface{}{[]string{"a", "b"}, 1, 2},
+		SelectStmt{dialect: Postgres}.
+			Select("*").
+			From("users").
+			Where(In("id", sub)).
+			Where("x IN ?", []int{1, 2}),
+	)
+	testSQL(t,
+		"SELECT * FROM users GROUP BY id HAVING (id IN (SELECT id FROM t WHERE tags = ?))",
+		[]interface{}{[]string{"a", "b"}},
+		Select("*").From("users").GroupBy("id").Having(In("id", sub)),
+	)
+	sql, values, err := In("id", sub).SQL()
+	require.NoError(t, err)
+	require.Equal(t, "(id IN (SELECT id FROM t WHERE tags = ?))", sql)
+	require.Equal(t, []interface{}{[]string{"a", "b"}}, values)
+}
+
+func TestSelect_NilPointerValue(t *testing.T) {
+	var name *string
+	var ids *[]int
+	testSQL(t,
+		"SELECT * FROM users WHERE (a = ? AND b = ? AND (c = ?))",
+		[]interface{}{name, ids, name},
+		Select("*").
+			From("users").
+			Where("a = ? AND b = ?", name, ids).
+			Where(Eq("c", name)),
+	)
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import "strings"
+
+// Split splits an SQL script into statements on semicolons. Semicolons inside
+// quoted strings and identifiers, comments and Postgres dollar quoted strings
+// such as function bodies are ignored, using the same rules as placeholders.
+// On MySQL a backslash escapes the next character in a quoted string. MySQL
+// DELIMITER commands at the start of a line change the delimiter for the
+// statements which follow. In CREATE TRIGGER, FUNCTION, PROCEDURE and EVENT
+// statements semicolons between BEGIN and the matching END are not split, such
+// as SQLite trigger bodies and Postgres BEGIN ATOMIC bodies. CASE ... END is
+// counted when matching, and END IF, END LOOP, END WHILE and END REPEAT are
+// ignored. Statements are trimmed and those containing only comments are
+// dropped.
+func Split(dialect Dialect, script string) []string {
+	var (
+		out        []string
+		cur        strings.Builder
+		hasContent bool
+		first      string
+		routine    bool
+		depth      int
+		lineStart  = true
+		delim      = ";"
+		backslash  = dialects[dialect].backslashEscapes()
+	)
+	flush := func() {
+		if hasContent {
+			out = append(out, strings.TrimSpace(cur.String()))
+		}
+		cur.Reset()
+		hasContent = false
+		first, routine, depth = "", false, 0
+	}
+
+	for i := 0; i < len(script); {
+		if lineStart && !hasContent {
+			if d, end, ok := delimiterCommand(script, i); ok {
+				cur.Reset()
+				delim = d
+				i = end
+				continue
+			}
+		}
+		if depth == 0 && strings.HasPrefix(script[i:], delim) {
+			flush()
+			i += len(delim)
+			lineStart = false
+			continue
+		}
+
+		if end := wordEnd(script, i); end > i {
+			word := strings.ToUpper(script[i:end])
+			switch {
+			case first == "":
+				first = word
+			case first == "CREATE" && depth == 0 && routineKeywords[word]:
+				routine = true
+			case routine && (word == "BEGIN" || word == "CASE"):
+				depth++
+			case routine && depth > 0 && word == "END":
+				if !endKeywords[strings.ToUpper(nextWord(script, end))] {
+					depth--
+				}
+			}
+			cur.WriteString(script[i:end])
+			hasContent = true
+			lineStart = false
+			i = end
+			continue
+		}
+
+		kind, end := scanToken(script, i, backslash)
+		content := kind != tokenComment
+		if kind == tokenText {
+			switch script[i] {
+			case ' ', '\t', '\r', '\n':
+				content = false
+			}
+		}
+		cur.WriteString(script[i:end])
+		hasContent = hasContent || content
+		lineStart = script[end-1] == '\n'
+		i = end
+	}
+	flush()
+	return out
+}
+
+// delimiterCommand parses a MySQL DELIMITER command on the line starting at i,
+// returning the delimiter and the index of the next line.
+func delimiterCommand(script string, i int) (string, int, bool) {
+	line := script[i:]
+	end := len(script)
+	if n := strings.IndexByte(line, '\n'); n >= 0 {
+		line = line[:n]
+		end = i + n + 1
+	}
+	fields := strings.Fields(line)
+	if len(fields) != 2 || !strings.EqualFold(fields[0], "DELIMITER") {
+		return "", 0, false
+	}
+	return fields[1], end, true
+}
+
+// routineKeywords are the objects created with a BEGIN ... END body.
+var routineKeywords = map[string]bool{
+	"TRIGGER": true, "FUNCTION": true, "PROCEDURE": true, "EVENT": true,
+}
+
+// endKeywords follow an END which closes a MySQL control statement rather than
+// a BEGIN or CASE.
+var endKeywords = map[string]bool{
+	"IF": true, "LOOP": true, "WHILE": true, "REPEAT": true,
+}
+
+// wordEnd returns the index after the keyword or unquoted identifier starting
+// at i, or i if there is none. Words prefixing a string such as E'...' are
+// left to scanToken.
+func wordEnd(script string, i int) int {
+	if i > 0 && isIdentByte(script[i-1]) {
+		return i
+	}
+	end := i
+	for end < len(script) && isIdentByte(script[end]) && script[end] != '$' {
+		end++
+	}
+	if end == i || script[i] >= '0' && script[i] <= '9' ||
+		end < len(script) && script[end] == '\'' {
+		return i
+	}
+	return end
+}
+
+// nextWord returns the word following i, skipping whitespace.
+func nextWord(script string, i int) string {
+	for i < len(script) && strings.IndexByte(" \t\r\n", script[i]) >= 0 {
+		i++
+	}
+	return script[i:wordEnd(script, i)]
+}
@@ -0,0 +1,85 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		script string
+		out    []string
+	}{
+		{"empty", " \n-- only a comment\n", nil},
+		{"simple", "create table a (id int);\ncreate table b (id int)", []string{
+			"create table a (id int)",
+			"create table b (id int)",
+		}},
+		{"quotes", `insert into a values ('a;b', "c;d", ` + "`e;f`" + `, 'it''s;');select 1;`, []string{
+			`insert into a values ('a;b', "c;d", ` + "`e;f`" + `, 'it''s;')`,
+			"select 1",
+		}},
+		{"comments", "select 1; -- trailing; comment\n/* block; */ select 2;", []string{
+			"select 1",
+			"-- trailing; comment\n/* block; */ select 2",
+		}},
+		{"dollar", "create function f() returns int as $$ select 1; $$ language sql;\n" +
+			"create function g() returns int as $body$ select $1; $body$ language sql;", []string{
+			"create function f() returns int as $$ select 1; $$ language sql",
+			"create function g() returns int as $body$ select $1; $body$ language sql",
+		}},
+		{"delimiter", "DELIMITER $$\ncreate procedure p() begin select 1; select 2; end$$\nDELIMITER ;\nselect 3;", []string{
+			"create procedure p() begin select 1; select 2; end",
+			"select 3",
+		}},
+		{"positional", "select $1;select $2", []string{"select $1", "select $2"}},
+	} {
+		require.Equal(t, tc.out, Split(Postgres, tc.script), tc.name)
+	}
+}
+
+func TestSplit_Backslash(t *testing.T) {
+	script := `insert into a values ('it\'s;', "a\";b");select 1;`
+	require.Equal(t, []string{
+		`insert into a values ('it\'s;', "a\";b")`,
+		"select 1",
+	}, Split(MySQL, script))
+	require.Equal(t, []string{`select 'a\'`, "select 1"}, Split(Postgres, `select 'a\';select 1`))
+	require.Equal(t, []string{
+		`insert into a values (E'it\'s;')`,
+		"select a$$b from t",
+	}, Split(Postgres, `insert into a values (E'it\'s;');select a$$b from t;`))
+}
+
+func TestSplit_Bodies(t *testing.T) {
+	trigger := "CREATE TRIGGER users_updated AFTER UPDATE ON users BEGIN\n" +
+		"  UPDATE users SET updated_at = CASE WHEN new.a THEN 1 ELSE 2 END WHERE id = new.id;\n" +
+		"  INSERT INTO log VALUES (new.id);\nEND"
+	require.Equal(t, []string{
+		"BEGIN",
+		trigger,
+		"COMMIT",
+		"select 'end;'",
+	}, Split(SQLite, "BEGIN;\n"+trigger+";\nCOMMIT;\nselect 'end;';"))
+
+	atomic := "create function one() returns int language sql begin atomic select 1; select 2; end"
+	require.Equal(t, []string{atomic, "select 3"}, Split(Postgres, atomic+";\nselect 3;"))
+
+	proc := "create procedure p(x int) begin\n  if x > 0 then select 1; end if;\n" +
+		"  while x > 0 do set x = x - 1; end while;\nend"
+	require.Equal(t, []string{proc, "select 2"}, Split(MySQL, proc+";select 2;"))
+
+	// Words are not matched inside identifiers or outside routines.
+	require.Equal(t, []string{
+		"create table begin_end (trigger_name text)",
+		"select case when a then 1 end from t",
+		"select 2",
+	}, Split(Postgres, "create table begin_end (trigger_name text);select case when a then 1 end from t;select 2"))
+}
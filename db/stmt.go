@@ -21,6 +21,15 @@ const (
 	lt
 	lte
 	gte
+	notIn
+	isNot
+	like
+	ilike
+	between
+	not
+	exists
+	notExists
+	group
 )
 
 func (o operator) String() string {
@@ -45,6 +54,24 @@ func (o operator) String() string {
 		return "IS"
 	case ne:
 		return "!="
+	case notIn:
+		return "NOT IN"
+	case isNot:
+		return "IS NOT"
+	case like:
+		return "LIKE"
+	case ilike:
+		return "ILIKE"
+	case between:
+		return "BETWEEN"
+	case not:
+		return "NOT"
+	case exists:
+		return "EXISTS"
+	case notExists:
+		return "NOT EXISTS"
+	case group:
+		return ""
 	default:
 		return "<unknown>"
 	}
@@ -59,33 +86,95 @@ type Statement struct {
 
 func (s Statement) isZero() bool { return s.operator == none }
 
-// And configures the And operator.
+// And configures the And operator. If either statement is empty the other
+// is returned.
 func (s Statement) And(c Statement) Statement {
+	if s.isZero() {
+		return c
+	}
+	if c.isZero() {
+		return s
+	}
 	return Statement{left: s, operator: and, right: c}
 }
 
-// Or configures an or operator.
+// Or configures an or operator. If either statement is empty the other is
+// returned.
 func (s Statement) Or(c Statement) Statement {
+	if s.isZero() {
+		return c
+	}
+	if c.isZero() {
+		return s
+	}
 	return Statement{left: s, operator: or, right: c}
 }
 
 // SQL implements the SQL interface.
 func (s Statement) SQL() (string, []interface{}, error) {
-	left, argsLeft, err := s.left.SQL()
+	sql, values, err := s.render(dialects[Generic], false)
+	return sql, argValues(values), err
+}
+
+// render writes the statement for a dialect. This allows operators such as
+// ILIKE to be emulated where the dialect does not support them.
+func (s Statement) render(m dialectMapper, quote bool) (string, []interface{}, error) {
+	if s.isZero() {
+		return "", nil, ErrStatementInvalid
+	}
+	right, argsRight, err := renderSQL(m, quote, s.right)
 	if err != nil {
 		return "", nil, err
 	}
-	right, argsRight, err := s.right.SQL()
+	if right == "" {
+		return "", nil, ErrStatementInvalid
+	}
+	switch s.operator {
+	case group:
+		return "(" + right + ")", argsRight, nil
+	case not, exists, notExists:
+		return "(" + s.operator.String() + " " + right + ")", argsRight, nil
+	}
+
+	left, argsLeft, err := renderSQL(m, quote, s.left)
 	if err != nil {
 		return "", nil, err
 	}
-	if left == "" || right == "" {
+	if left == "" {
 		return "", nil, ErrStatementInvalid
 	}
-	sql := "(" + left + " " + s.operator.String() + " " + right + ")"
+	var sql string
+	if s.operator == ilike && !m.supportsILike() {
+		sql = "(LOWER(" + left + ") LIKE LOWER(" + right + "))"
+	} else {
+		sql = "(" + left + " " + s.operator.String() + " " + right + ")"
+	}
 	return sql, append(argsLeft, argsRight...), nil
 }
 
+// renderSQL renders a nested SQL for a dialect, quoting predicate columns if
+// quote is set.
+func renderSQL(m dialectMapper, quote bool, s SQL) (string, []interface{}, error) {
+	switch v := s.(type) {
+	case Statement:
+		return v.render(m, quote)
+	case where:
+		return v.render(m, quote)
+	case column:
+		if quote {
+			return m.identifier(string(v)), nil, nil
+		}
+	}
+	return unbound(s)
+}
+
+// column is the column on the left of a predicate.
+type column string
+
+func (c column) SQL() (string, []interface{}, error) {
+	return string(c), nil, nil
+}
+
 // NotEq sets col != val.
 func NotEq(col string, val interface{}) Statement { return stmt(ne, col, val) }
 
@@ -110,7 +199,59 @@ func In(col string, val interface{}) Statement { return stmt(in, col, val) }
 // Is sets col is val.
 func Is(col string, val interface{}) Statement { return stmt(is, col, val) }
 
-// EqAllMap sets (key = val) for every parameter joining with AND.
+// NotIn sets col not in (val).
+func NotIn(col string, val interface{}) Statement { return stmt(notIn, col, val) }
+
+// Like sets col like val.
+func Like(col string, val interface{}) Statement { return stmt(like, col, val) }
+
+// ILike sets col ilike val. On dialects without ILIKE this is written as
+// LOWER(col) LIKE LOWER(val).
+func ILike(col string, val interface{}) Statement { return stmt(ilike, col, val) }
+
+// Between sets col between low and high.
+func Between(col string, low, high interface{}) Statement {
+	return Statement{
+		left:     column(col),
+		operator: between,
+		right:    rangeSQL{valueSQL(low), valueSQL(high)},
+	}
+}
+
+// IsNull sets col is null.
+func IsNull(col string) Statement { return stmt(is, col, Null) }
+
+// IsNotNull sets col is not null.
+func IsNotNull(col string) Statement { return stmt(isNot, col, Null) }
+
+// Not negates a statement.
+func Not(s Statement) Statement {
+	if s.isZero() {
+		return s
+	}
+	return Statement{operator: not, right: s}
+}
+
+// Exists sets exists (sel).
+func Exists(sel SelectStmt) Statement {
+	return Statement{operator: exists, right: parens{sel}}
+}
+
+// NotExists sets not exists (sel).
+func NotExists(sel SelectStmt) Statement {
+	return Statement{operator: notExists, right: parens{sel}}
+}
+
+// Any compares a column against any row of a subquery, for example
+// Eq("id", Any(sel)).
+func Any(sel SelectStmt) SQL { return quantified{"ANY", sel} }
+
+// All compares a column against all rows of a subquery, for example
+// Gt("total", All(sel)).
+func All(sel SelectStmt) SQL { return quantified{"ALL", sel} }
+
+// EqAllMap sets (key = val) for every parameter joining with AND. An empty map
+// returns an empty statement which is ignored by Where.
 func EqAllMap(m map[string]interface{}) (s Statement) {
 	for _, k := range mapKeys(m) {
 		v := m[k]
@@ -123,7 +264,8 @@ func EqAllMap(m map[string]interface{}) (s Statement) {
 	return
 }
 
-// EqAnyMap sets (key = val) for every parameter joining with OR.
+// EqAnyMap sets (key = val) for every parameter joining with OR. An empty map
+// returns an empty statement which is ignored by Where.
 func EqAnyMap(m map[string]interface{}) (s Statement) {
 	for _, k := range mapKeys(m) {
 		v := m[k]
@@ -140,20 +282,26 @@ func EqAnyMap(m map[string]interface{}) (s Statement) {
 var Null = Raw("NULL")
 
 func stmt(op operator, col string, value interface{}) Statement {
-	var right SQL
+	return Statement{
+		left:     column(col),
+		operator: op,
+		right:    valueSQL(value),
+	}
+}
+
+// valueSQL returns the SQL for a bound value. Values implementing SQL are
+// written inline and selects are wrapped as subqueries.
+func valueSQL(value interface{}) SQL {
+	var out SQL
 	if s, ok := value.(SQL); ok {
-		right = s
+		out = s
 	} else {
-		right = RawWithValues("?", value)
-	}
-	if s, ok := right.(SelectStmt); ok {
-		right = parens{s}
+		out = RawWithValues("?", value)
 	}
-	return Statement{
-		left:     Raw(col),
-		operator: op,
-		right:    right,
+	if s, ok := out.(SelectStmt); ok {
+		out = parens{s}
 	}
+	return out
 }
 
 func mapKeys(m map[string]interface{}) []string {
@@ -170,6 +318,30 @@ func mapKeys(m map[string]interface{}) []string {
 type parens struct { sql SQL }
 
 func (q parens) SQL() (string, []interface{}, error) {
-	sql, values, err := q.sql.SQL()
+	sql, values, err := unbound(q.sql)
 	return "(" + sql + ")", values, err
 }
+
+type rangeSQL struct{ low, high SQL }
+
+func (q rangeSQL) SQL() (string, []interface{}, error) {
+	low, lowValues, err := unbound(q.low)
+	if err != nil {
+		return "", nil, err
+	}
+	high, highValues, err := unbound(q.high)
+	if err != nil {
+		return "", nil, err
+	}
+	return low + " AND " + high, append(lowValues, highValues...), nil
+}
+
+type quantified struct {
+	op  string
+	sel SelectStmt
+}
+
+func (q quantified) SQL() (string, []interface{}, error) {
+	sql, values, err := unbound(q.sel)
+	return q.op + " (" + sql + ")", values, err
+}
@@ -100,3 +100,52 @@ func TestEqAnyMap(t *testing.T) {
 	require.Equal(t, "((x = ?) OR (y = ?))", sql)
 	require.Equal(t, []interface{}{1, 2}, args)
 }
+
+func TestPredicates(t *testing.T) {
+	sel := Select("id").From("groups").Where("kind = ?", "a")
+	for _, tc := range []struct {
+		stmt Statement
+		sql  string
+		args []interface{}
+	}{
+		{Not(Eq("a", 1)), "(NOT (a = ?))", []interface{}{1}},
+		{NotIn("a", []int{1, 2}), "(a NOT IN ?)", []interface{}{[]int{1, 2}}},
+		{Like("a", "x%"), "(a LIKE ?)", []interface{}{"x%"}},
+		{ILike("a", "x%"), "(LOWER(a) LIKE LOWER(?))", []interface{}{"x%"}},
+		{Between("a", 1, 2), "(a BETWEEN ? AND ?)", []interface{}{1, 2}},
+		{IsNull("a"), "(a IS NULL)", nil},
+		{IsNotNull("a"), "(a IS NOT NULL)", nil},
+		{Exists(sel), "(EXISTS (SELECT id FROM groups WHERE kind = ?))", []interface{}{"a"}},
+		{NotExists(sel), "(NOT EXISTS (SELECT id FROM groups WHERE kind = ?))", []interface{}{"a"}},
+		{Eq("a", Any(sel)), "(a = ANY (SELECT id FROM groups WHERE kind = ?))", []interface{}{"a"}},
+		{Gt("a", All(sel)), "(a > ALL (SELECT id FROM groups WHERE kind = ?))", []interface{}{"a"}},
+	} {
+		sql, args, err := tc.stmt.SQL()
+		require.NoError(t, err)
+		require.Equal(t, tc.sql, sql)
+		require.Equal(t, tc.args, args)
+	}
+}
+
+func TestILikePostgres(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users WHERE (name ILIKE $1)",
+		[]interface{}{"a%"},
+		SelectStmt{dialect: Postgres}.Select("*").From("users").Where(ILike("name", "a%")),
+	)
+}
+
+func TestAndOrEmpty(t *testing.T) {
+	sql, args, err := Statement{}.And(Eq("a", 1)).Or(Statement{}).SQL()
+	require.NoError(t, err)
+	require.Equal(t, "(a = ?)", sql)
+	require.Equal(t, []interface{}{1}, args)
+}
+
+func TestEqAllMapEmpty(t *testing.T) {
+	testSQL(t,
+		"SELECT * FROM users",
+		nil,
+		Select("*").From("users").Where(EqAllMap(nil)),
+	)
+}
@@ -15,6 +15,7 @@ func Update(table string) UpdateStmt { return UpdateStmt{table: table} }
 // UpdateStmt represents an UPDATE in SQL.
 type UpdateStmt struct {
 	dialect Dialect
+	quote   bool
 	table   string
 	columns []string
 	values  []interface{}
@@ -31,7 +32,7 @@ func (i UpdateStmt) Table(table string) UpdateStmt {
 
 // Columns sets the colums for the update.
 func (i UpdateStmt) Columns(cols ...string) UpdateStmt {
-	i.columns = cols
+	i.columns = append([]string(nil), cols...)
 	return i
 }
 
@@ -41,16 +42,64 @@ func (i UpdateStmt) Where(where interface{}, args ...interface{}) UpdateStmt {
 	return i
 }
 
-// Values sets the values for the update.
+// OrWhere configures the WHERE block joining the condition with OR.
+func (i UpdateStmt) OrWhere(where interface{}, args ...interface{}) UpdateStmt {
+	i.sel = i.sel.OrWhere(where, args...)
+	return i
+}
+
+// WhereGroup adds a parenthesized group of conditions to the WHERE block. See
+// SelectStmt.WhereGroup.
+func (i UpdateStmt) WhereGroup(fn func(SelectStmt) SelectStmt) UpdateStmt {
+	i.sel = i.sel.WhereGroup(fn)
+	return i
+}
+
+// From configures a table to update from. This renders as UPDATE ... FROM on
+// Postgres and as a multiple table UPDATE on MySQL. The condition relating the
+// tables should be configured using Where. SQLite only supports UPDATE ...
+// FROM from version 3.33, newer than the version bundled with the go-sqlite3
+// driver, so it is rejected.
+func (i UpdateStmt) From(table string) UpdateStmt {
+	i.sel = i.sel.From(table)
+	return i
+}
+
+// Join adds an inner join to the update. MySQL renders UPDATE a JOIN b ON ...
+// while Postgres lists the table in the FROM clause and moves the join
+// condition into the WHERE clause. Joins are rejected on SQLite, see From.
+func (i UpdateStmt) Join(table, on string, values ...interface{}) UpdateStmt {
+	i.sel = i.sel.joins("INNER", table, on, values...)
+	return i
+}
+
+// OrderBy configures the ORDER BY clause. This is only supported on MySQL for
+// single table updates. SQLite only supports this when compiled with
+// SQLITE_ENABLE_UPDATE_DELETE_LIMIT, which the go-sqlite3 driver is not, so it
+// is rejected.
+func (i UpdateStmt) OrderBy(orderBy ...string) UpdateStmt {
+	i.sel = i.sel.OrderBy(orderBy...)
+	return i
+}
+
+// Limit configures the LIMIT clause. This is only supported on MySQL for single
+// table updates.
+func (i UpdateStmt) Limit(limit int) UpdateStmt {
+	i.sel = i.sel.Limit(limit)
+	return i
+}
+
+// Values sets the values for the update. Values implementing SQL, such as Raw,
+// are written inline instead of being bound.
 func (i UpdateStmt) Values(vals ...interface{}) UpdateStmt {
-	i.values = vals
+	i.values = append([]interface{}(nil), vals...)
 	return i
 }
 
 // Value configures a single value for the query.
 func (i UpdateStmt) Value(name string, val interface{}) UpdateStmt {
-	i.values = append(i.values, val)
-	i.columns = append(i.columns, name)
+	i.values = append(i.values[:len(i.values):len(i.values)], val)
+	i.columns = append(i.columns[:len(i.columns):len(i.columns)], name)
 	return i
 }
 
@@ -61,20 +110,28 @@ func (i UpdateStmt) Record(obj interface{}, fields ...string) UpdateStmt {
 		i.err = err
 		return i
 	}
-	i.columns = append(i.columns, cols...)
-	i.values = append(i.values, vals...)
+	i.columns = append(i.columns[:len(i.columns):len(i.columns)], cols...)
+	i.values = append(i.values[:len(i.values):len(i.values)], vals...)
 	return i
 }
 
 // SQL implements the SQL interface.
 func (i UpdateStmt) SQL() (string, []interface{}, error) {
+	sql, values, err := i.unboundSQL()
+	if err != nil {
+		return "", nil, err
+	}
+	return dialects[i.dialect].rebind(sql), argValues(values), nil
+}
+
+func (i UpdateStmt) unboundSQL() (string, []interface{}, error) {
+	if i.err != nil {
+		return "", nil, i.err
+	}
 	if len(i.columns) != len(i.values) {
 		return "", nil, ErrStatementInvalid
 	}
-	if i.sel.err != nil {
-		return "", nil, i.sel.err
-	}
-	i.sel = i.sel.parseWhere()
-	sql := dialects[i.dialect].update(i)
-	return sql, append(i.values, i.sel.values...), i.err
+	b := &builder{quote: i.quote}
+	dialects[i.dialect].update(b, i)
+	return b.result()
 }
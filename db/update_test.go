@@ -6,6 +6,8 @@
 package db
 
 import (
+	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -75,3 +77,93 @@ func TestUpdate_SQLWhereStmt(t *testing.T) {
 			Where(Eq("c1", 1)),
 	)
 }
+
+func TestUpdate_SQLFrom(t *testing.T) {
+	testSQL(t,
+		"UPDATE users SET name=groups.name FROM groups WHERE users.group_id = groups.id",
+		nil,
+		Update("users").
+			Columns("name").
+			Values(Raw("groups.name")).
+			From("groups").
+			Where("users.group_id = groups.id"),
+	)
+}
+
+func TestUpdate_SQLJoin(t *testing.T) {
+	testSQL(t,
+		"UPDATE users SET c1=$1 FROM groups WHERE (users.group_id = groups.id AND groups.kind = $2) AND (users.id = $3)",
+		[]interface{}{"1", "a", 1},
+		UpdateStmt{dialect: Postgres, table: "users"}.
+			Value("c1", "1").
+			Join("groups", "users.group_id = groups.id AND groups.kind = ?", "a").
+			Where("users.id = ?", 1),
+	)
+}
+
+func TestUpdate_SQLMySQLJoin(t *testing.T) {
+	testSQL(t,
+		"UPDATE users INNER JOIN groups ON users.group_id = groups.id AND groups.kind = ? SET c1=? WHERE users.id = ?",
+		[]interface{}{"a", "1", 1},
+		UpdateStmt{dialect: MySQL, table: "users"}.
+			Value("c1", "1").
+			Join("groups", "users.group_id = groups.id AND groups.kind = ?", "a").
+			Where("users.id = ?", 1),
+	)
+}
+
+func TestUpdate_SQLMySQLFrom(t *testing.T) {
+	testSQL(t,
+		"UPDATE users CROSS JOIN groups SET c1=? WHERE users.group_id = groups.id",
+		[]interface{}{"1"},
+		UpdateStmt{dialect: MySQL, table: "users"}.
+			Value("c1", "1").
+			From("groups").
+			Where("users.group_id = groups.id"),
+	)
+}
+
+func TestUpdate_SQLMySQLOrderLimit(t *testing.T) {
+	testSQL(t,
+		"UPDATE users SET c1=? WHERE c1 = ? ORDER BY id LIMIT 100",
+		[]interface{}{"1", "0"},
+		UpdateStmt{dialect: MySQL, table: "users"}.
+			Value("c1", "1").
+			Where("c1 = ?", "0").
+			OrderBy("id").
+			Limit(100),
+	)
+}
+
+func TestUpdate_SQLOrderLimitUnsupported(t *testing.T) {
+	_, _, err := UpdateStmt{dialect: Postgres, table: "users"}.
+		Value("c1", "1").
+		Limit(100).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+
+	_, _, err = UpdateStmt{dialect: MySQL, table: "users"}.
+		Value("c1", "1").
+		Join("groups", "users.group_id = groups.id").
+		Limit(100).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestUpdate_SQLiteFrom(t *testing.T) {
+	d, err := Open("sqlite3", "file:updatefrom?mode=memory&cache=shared")
+	require.Nil(t, err)
+	defer d.Close()
+
+	ctx := context.Background()
+	require.Nil(t, d.Exec(ctx, Raw("create table users (id int primary key, name text)")).Err())
+	require.Nil(t, d.Exec(ctx, Raw("create table groups (id int primary key, name text)")).Err())
+
+	// The bundled SQLite predates UPDATE ... FROM.
+	require.Error(t, d.Exec(ctx, Raw("update users set name = groups.name from groups where groups.id = users.id")).Err())
+
+	err = d.Exec(ctx, d.Update("users").From("groups").Value("name", Raw("groups.name")).Where("groups.id = users.id")).Err()
+	require.True(t, errors.Is(err, ErrUnsupported))
+	err = d.Exec(ctx, d.Update("users").Join("groups", "groups.id = users.id").Value("name", "a")).Err()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
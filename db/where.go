@@ -5,7 +5,10 @@
 
 package db
 
-import "reflect"
+import (
+	"database/sql/driver"
+	"reflect"
+)
 
 type where struct {
 	sql SQL // Bare where clause.
@@ -20,6 +23,11 @@ func (q where) where(op operator, where interface{}, values ...interface{}) wher
 		} else {
 			next = Raw(v) // More efficient implementation if no values.
 		}
+	case Statement:
+		if v.isZero() {
+			return q // Empty statements are ignored.
+		}
+		next = v
 	case SQL:
 		next = v
 	default:
@@ -34,30 +42,121 @@ func (q where) where(op operator, where interface{}, values ...interface{}) wher
 	return q
 }
 
-func (q where) SQL() (sql string, values []interface{}, err error) {
-	if q.sql != nil {
-		sql, values, err = q.sql.SQL()
-	} else {
+func (q where) SQL() (string, []interface{}, error) {
+	sql, values, err := q.render(dialects[Generic], false)
+	return sql, argValues(values), err
+}
+
+// render writes the where clause for a dialect, expanding slice values. Values
+// wrapped by Array are left wrapped so that a nested where clause rendered
+// again by an outer one is not expanded.
+func (q where) render(m dialectMapper, quote bool) (string, []interface{}, error) {
+	if q.sql == nil {
 		return "", nil, nil // No statements.
 	}
-	for i, arg := range values {
-		if ok, l, inVals := isSlice(arg); ok {
-			sql, err = insertQuestions(sql, i, l)
-			inVals = append(inVals, values[i+1:]...)
-			values = append(values[:i], inVals...)
+	sql, values, err := renderSQL(m, quote, q.sql)
+	if err != nil {
+		return "", nil, err
+	}
+	out := make([]interface{}, 0, len(values))
+	for _, arg := range values {
+		ok, l, inVals := isSlice(arg)
+		if !ok {
+			out = append(out, arg)
+			continue
+		}
+		// Earlier slices have already been expanded so the placeholder index
+		// is the number of values written so far.
+		sql, err = insertQuestions(sql, len(out), l, m.backslashEscapes())
+		if err != nil {
+			return "", nil, err
 		}
+		out = append(out, inVals...)
+	}
+	return sql, out, nil
+}
+
+// group returns the where clause as a single parenthesized statement.
+func (q where) group() Statement {
+	if q.sql == nil {
+		return Statement{}
+	}
+	if s, ok := q.sql.(Statement); ok {
+		return s
+	}
+	return Statement{operator: group, right: q.sql}
+}
+
+// List wraps a slice so that it is always expanded into a list of
+// placeholders, for example "id IN ?" becomes "id IN (?, ?, ?)". Plain slices
+// are expanded by default, List can be used for slice types which implement
+// driver.Valuer.
+func List(values interface{}) interface{} { return list{values} }
+
+// Array wraps a slice so that it is passed as a single parameter instead of
+// being expanded, for drivers which accept slices as array parameters. The
+// slice is unwrapped before being passed to the driver.
+func Array(values interface{}) interface{} { return array{values} }
+
+type list struct{ values interface{} }
+
+type array struct{ values interface{} }
+
+// argValues returns the values of a complete statement with the values
+// wrapped by List or Array unwrapped.
+func argValues(values []interface{}) []interface{} {
+	if values == nil {
+		return nil
+	}
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = argValue(v)
 	}
-	return
+	return out
 }
 
-// isSlice checks if the value is a slice, if it is, it returns the length and a
-// representation of the slice as an []interface{}.
+// argValue unwraps values wrapped by List or Array.
+func argValue(arg interface{}) interface{} {
+	switch v := arg.(type) {
+	case list:
+		return v.values
+	case array:
+		return v.values
+	}
+	return arg
+}
+
+// isSlice checks if the value should be expanded as a slice, if it is, it
+// returns the length and a representation of the slice as an []interface{}.
+// Byte slices, values implementing driver.Valuer and values wrapped by Array
+// are not expanded.
 func isSlice(i interface{}) (bool, int, []interface{}) {
+	switch v := i.(type) {
+	case list:
+		if ok, l, arr := sliceValues(v.values); ok {
+			return ok, l, arr
+		}
+		return true, 1, []interface{}{v.values}
+	case array, driver.Valuer:
+		return false, 0, nil
+	}
+	return sliceValues(i)
+}
+
+func sliceValues(i interface{}) (bool, int, []interface{}) {
 	v := reflect.ValueOf(i)
 	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return false, 0, nil // Passed through unchanged and bound as NULL.
+		}
 		v = v.Elem()
+		if v.CanInterface() {
+			if _, ok := v.Interface().(driver.Valuer); ok {
+				return false, 0, nil
+			}
+		}
 	}
-	if v.Kind() == reflect.Slice {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
 		l := v.Len()
 		arr := make([]interface{}, l)
 		for i := 0; i < l; i++ {
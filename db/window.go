@@ -0,0 +1,115 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Window represents a window specification for window functions. The zero
+// value is an empty window covering all rows:
+//
+//	Select("id", As(Over(RowNumber(), Window{}.
+//		PartitionBy("user_id").
+//		OrderBy("created_at DESC")), "n")).
+//		From("orders")
+type Window struct {
+	base        string
+	partitionBy []string
+	orderBy     []string
+	frame       string
+}
+
+// Base configures the window to extend a named window defined on the select.
+func (w Window) Base(name string) Window {
+	w.base = name
+	return w
+}
+
+// PartitionBy configures the PARTITION BY clause.
+func (w Window) PartitionBy(cols ...string) Window {
+	w.partitionBy = append([]string(nil), cols...)
+	return w
+}
+
+// OrderBy configures the ORDER BY clause.
+func (w Window) OrderBy(cols ...string) Window {
+	w.orderBy = append([]string(nil), cols...)
+	return w
+}
+
+// Frame configures the frame clause, such as "ROWS BETWEEN UNBOUNDED PRECEDING
+// AND CURRENT ROW" for a running total.
+func (w Window) Frame(frame string) Window {
+	w.frame = frame
+	return w
+}
+
+// String returns the window specification without surrounding parentheses.
+func (w Window) String() string {
+	var parts []string
+	if w.base != "" {
+		parts = append(parts, w.base)
+	}
+	if w.partitionBy != nil {
+		parts = append(parts, "PARTITION BY "+strings.Join(w.partitionBy, ", "))
+	}
+	if w.orderBy != nil {
+		parts = append(parts, "ORDER BY "+strings.Join(w.orderBy, ", "))
+	}
+	if w.frame != "" {
+		parts = append(parts, w.frame)
+	}
+	return strings.Join(parts, " ")
+}
+
+// namedWindow is a window definition in the WINDOW clause of a select.
+type namedWindow struct {
+	name   string
+	window Window
+}
+
+// Window adds a named window definition to the WINDOW clause which can be
+// referenced using OverWindow.
+func (q SelectStmt) Window(name string, w Window) SelectStmt {
+	q.windows = append(q.windows[:len(q.windows):len(q.windows)], namedWindow{name: name, window: w})
+	return q
+}
+
+// Over returns fn OVER (window).
+func Over(fn string, w Window) string { return fn + " OVER (" + w.String() + ")" }
+
+// OverWindow returns fn OVER name for a named window.
+func OverWindow(fn, name string) string { return fn + " OVER " + name }
+
+// RowNumber returns ROW_NUMBER().
+func RowNumber() string { return "ROW_NUMBER()" }
+
+// Rank returns RANK().
+func Rank() string { return "RANK()" }
+
+// DenseRank returns DENSE_RANK().
+func DenseRank() string { return "DENSE_RANK()" }
+
+// Ntile returns NTILE(n).
+func Ntile(n int) string { return "NTILE(" + strconv.Itoa(n) + ")" }
+
+// Lag returns LAG(expr, offset).
+func Lag(expr string, offset int) string {
+	return "LAG(" + expr + ", " + strconv.Itoa(offset) + ")"
+}
+
+// Lead returns LEAD(expr, offset).
+func Lead(expr string, offset int) string {
+	return "LEAD(" + expr + ", " + strconv.Itoa(offset) + ")"
+}
+
+// FirstValue returns FIRST_VALUE(expr).
+func FirstValue(expr string) string { return "FIRST_VALUE(" + expr + ")" }
+
+// LastValue returns LAST_VALUE(expr).
+func LastValue(expr string) string { return "LAST_VALUE(" + expr + ")" }
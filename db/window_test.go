@@ -0,0 +1,57 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow_String(t *testing.T) {
+	require.Equal(t, "", Window{}.String())
+	require.Equal(t,
+		"w PARTITION BY a, b ORDER BY c DESC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW",
+		Window{}.
+			Base("w").
+			PartitionBy("a", "b").
+			OrderBy("c DESC").
+			Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW").
+			String(),
+	)
+}
+
+func TestWindow_Functions(t *testing.T) {
+	require.Equal(t, "ROW_NUMBER() OVER ()", Over(RowNumber(), Window{}))
+	require.Equal(t, "RANK() OVER w", OverWindow(Rank(), "w"))
+	require.Equal(t, "DENSE_RANK()", DenseRank())
+	require.Equal(t, "NTILE(4)", Ntile(4))
+	require.Equal(t, "LAG(total, 1)", Lag("total", 1))
+	require.Equal(t, "LEAD(total, 2)", Lead("total", 2))
+	require.Equal(t, "FIRST_VALUE(total)", FirstValue("total"))
+	require.Equal(t, "LAST_VALUE(total)", LastValue("total"))
+}
+
+func TestWindow_SQLSelect(t *testing.T) {
+	testSQL(t,
+		"SELECT id,ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY created_at DESC) AS n,"+
+			"SUM(total) OVER (w ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) AS running,"+
+			"LAG(total, 1) OVER w AS previous "+
+			"FROM orders WHERE total > $1 WINDOW w AS (PARTITION BY user_id ORDER BY created_at) ORDER BY id",
+		[]interface{}{0},
+		SelectStmt{dialect: Postgres}.
+			Select(
+				"id",
+				As(Over(RowNumber(), Window{}.PartitionBy("user_id").OrderBy("created_at DESC")), "n"),
+				As(Over(Sum("total"), Window{}.Base("w").Frame("ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW")), "running"),
+				As(OverWindow(Lag("total", 1), "w"), "previous"),
+			).
+			From("orders").
+			Where("total > ?", 0).
+			Window("w", Window{}.PartitionBy("user_id").OrderBy("created_at")).
+			OrderBy("id"),
+	)
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+// cte represents a common table expression in a WITH clause.
+type cte struct {
+	name      string
+	columns   []string
+	sql       SQL
+	recursive bool
+}
+
+// With adds a common table expression to the WITH clause of the select. The
+// values bound in the expression come before the values of the main query.
+func (q SelectStmt) With(name string, sql SQL) SelectStmt {
+	q.with = append(q.with[:len(q.with):len(q.with)], cte{name: name, sql: sql})
+	return q
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause of
+// the select. This renders the clause as WITH RECURSIVE.
+func (q SelectStmt) WithRecursive(name string, cols []string, sql SQL) SelectStmt {
+	q.with = append(q.with[:len(q.with):len(q.with)], cte{name: name, columns: append([]string(nil), cols...), sql: sql, recursive: true})
+	return q
+}
+
+// With adds a common table expression to the WITH clause of the insert. On
+// MySQL this is only supported with FromSelect.
+func (i InsertStmt) With(name string, sql SQL) InsertStmt {
+	i.with = append(i.with[:len(i.with):len(i.with)], cte{name: name, sql: sql})
+	return i
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the insert.
+func (i InsertStmt) WithRecursive(name string, cols []string, sql SQL) InsertStmt {
+	i.with = append(i.with[:len(i.with):len(i.with)], cte{name: name, columns: append([]string(nil), cols...), sql: sql, recursive: true})
+	return i
+}
+
+// With adds a common table expression to the WITH clause of the update.
+func (i UpdateStmt) With(name string, sql SQL) UpdateStmt {
+	i.sel = i.sel.With(name, sql)
+	return i
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the update.
+func (i UpdateStmt) WithRecursive(name string, cols []string, sql SQL) UpdateStmt {
+	i.sel = i.sel.WithRecursive(name, cols, sql)
+	return i
+}
+
+// With adds a common table expression to the WITH clause of the delete.
+func (q DeleteStmt) With(name string, sql SQL) DeleteStmt {
+	q.sel = q.sel.With(name, sql)
+	return q
+}
+
+// WithRecursive adds a recursive common table expression to the WITH clause
+// of the delete.
+func (q DeleteStmt) WithRecursive(name string, cols []string, sql SQL) DeleteStmt {
+	q.sel = q.sel.WithRecursive(name, cols, sql)
+	return q
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2018 Colin Walker
+//
+// This software may be modified and distributed under the terms
+// of the MIT license.  See the LICENSE file for details.
+
+package db
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWith_SQLSelect(t *testing.T) {
+	testSQL(t,
+		"WITH active AS (SELECT id FROM users WHERE active = ?) SELECT * FROM orders WHERE ((user_id IN (SELECT id FROM active)) AND total > ?)",
+		[]interface{}{true, 10},
+		Select("*").
+			With("active", Select("id").From("users").Where("active = ?", true)).
+			From("orders").
+			Where(In("user_id", Select("id").From("active"))).
+			Where("total > ?", 10),
+	)
+}
+
+func TestWith_SQLRecursivePostgres(t *testing.T) {
+	d := New(WithDialect(Postgres))
+	testSQL(t,
+		"WITH RECURSIVE tree (id, parent_id) AS (SELECT id, parent_id FROM orgs WHERE id = $1 UNION ALL SELECT o.id, o.parent_id FROM orgs o JOIN tree t ON o.parent_id = t.id), named AS (SELECT id FROM orgs WHERE name = $2) SELECT * FROM tree WHERE id != $3",
+		[]interface{}{1, "a", 2},
+		d.Select("*").
+			WithRecursive("tree", []string{"id", "parent_id"}, RawWithValues(
+				"SELECT id, parent_id FROM orgs WHERE id = ? "+
+					"UNION ALL "+
+					"SELECT o.id, o.parent_id FROM orgs o JOIN tree t ON o.parent_id = t.id",
+				1,
+			)).
+			With("named", d.Select("id").From("orgs").Where("name = ?", "a")).
+			From("tree").
+			Where("id != ?", 2),
+	)
+}
+
+func TestWith_SQLInsert(t *testing.T) {
+	testSQL(t,
+		"WITH old AS (SELECT id FROM users WHERE age > $1) INSERT INTO archive (id) SELECT id FROM old",
+		[]interface{}{90},
+		InsertStmt{dialect: Postgres}.
+			With("old", Select("id").From("users").Where("age > ?", 90)).
+			Into("archive").
+			Columns("id").
+			FromSelect(Select("id").From("old")),
+	)
+}
+
+func TestWith_SQLInsertMySQL(t *testing.T) {
+	testSQL(t,
+		"INSERT INTO archive (id) WITH old AS (SELECT id FROM users WHERE age > ?) SELECT id FROM old",
+		[]interface{}{90},
+		InsertStmt{dialect: MySQL}.
+			With("old", Select("id").From("users").Where("age > ?", 90)).
+			Into("archive").
+			Columns("id").
+			FromSelect(Select("id").From("old")),
+	)
+
+	_, _, err := InsertStmt{dialect: MySQL}.
+		With("old", Select("id").From("users")).
+		Into("archive").
+		Value("id", 1).
+		SQL()
+	require.True(t, errors.Is(err, ErrUnsupported))
+}
+
+func TestWith_SQLUpdate(t *testing.T) {
+	testSQL(t,
+		"WITH old AS (SELECT id FROM users WHERE age > ?) UPDATE users SET active=? WHERE (id IN (SELECT id FROM old))",
+		[]interface{}{90, false},
+		Update("users").
+			With("old", Select("id").From("users").Where("age > ?", 90)).
+			Value("active", false).
+			Where(In("id", Select("id").From("old"))),
+	)
+}
+
+func TestWith_SQLDelete(t *testing.T) {
+	testSQL(t,
+		"WITH old AS (SELECT id FROM users WHERE age > ?) DELETE FROM users WHERE (id IN (SELECT id FROM old))",
+		[]interface{}{90},
+		Delete().
+			With("old", Select("id").From("users").Where("age > ?", 90)).
+			From("users").
+			Where(In("id", Select("id").From("old"))),
+	)
+}
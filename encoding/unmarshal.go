@@ -91,6 +91,35 @@ func fieldsByTraversal(v reflect.Value, traversals [][]int, values []interface{}
 	return nil
 }
 
+// Assign sets the field mapped to column in obj to value. The value is
+// converted using the same rules as Decode and a nil value leaves the field
+// untouched. The obj must be a pointer to a struct.
+func (e Encoder) Assign(obj interface{}, column string, value interface{}) error {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrRequiresPtr
+	}
+	if reflectx.Deref(v.Type()).Kind() != reflect.Struct {
+		return errors.New("argument not a struct")
+	}
+
+	m := DefaultMapper
+	if e.mapper != nil {
+		m = e.mapper
+	}
+
+	fields := m.TraversalsByName(v.Type(), []string{column})
+	values := make([]interface{}, 1)
+	err := fieldsByTraversal(v, fields, values, e.unsafe)
+	if err != nil {
+		return err
+	}
+	if s, ok := values[0].(sql.Scanner); ok {
+		return s.Scan(value)
+	}
+	return nil
+}
+
 // Unmarshal will run Decode with the default Decoder configuration.
 func Unmarshal(dest interface{}, rows *sql.Rows) error {
 	return Encoder{}.Decode(dest, rows)
@@ -443,3 +443,39 @@ func TestUnmarshal_RawRow(t *testing.T) {
 		spew.Dump(dest)
 	})
 }
+
+func TestAssign(t *testing.T) {
+	type BaseType struct {
+		ID int
+	}
+	type obj struct {
+		BaseType
+		Name string
+	}
+	dest := &obj{Name: "name"}
+	require.Nil(t, NewEncoder().Assign(dest, "id", int64(10)))
+	require.Equal(t, 10, dest.ID)
+	require.Equal(t, "name", dest.Name)
+
+	require.Nil(t, NewEncoder().Assign(dest, "id", nil))
+	require.Equal(t, 10, dest.ID)
+}
+
+func TestAssign_Missing(t *testing.T) {
+	type obj struct {
+		ID int
+	}
+	err := NewEncoder().Assign(&obj{}, "other", 1)
+	require.Equal(t, ErrMissingDestination, err)
+
+	err = NewEncoder().Unsafe().Assign(&obj{}, "other", 1)
+	require.Nil(t, err)
+}
+
+func TestAssign_NonPtr(t *testing.T) {
+	type obj struct {
+		ID int
+	}
+	err := NewEncoder().Assign(obj{}, "id", 1)
+	require.Equal(t, ErrRequiresPtr, err)
+}
@@ -8,14 +8,12 @@ import (
 )
 
 func createTable(d db.DB) error {
-	return d.Exec(context.Background(), db.Raw(
-		`create table users (
-			id integer primary key autoincrement,
-			email text,
-			created_at timestamp default current_timestamp not null,
-			updated_at timestamp default current_timestamp not null
-		)`,
-	)).Err()
+	return d.Exec(context.Background(), d.CreateTable("users").
+		Column("id", db.Serial).
+		Column("email", db.Text).
+		Column("created_at", db.Timestamp, db.NotNull(), db.Default(db.Raw("current_timestamp"))).
+		Column("updated_at", db.Timestamp, db.NotNull(), db.Default(db.Raw("current_timestamp"))),
+	).Err()
 }
 
 // Base is a base type for database objects.
@@ -43,8 +41,8 @@ func (rep *UserRepo) Save(ctx context.Context, u *User) error {
 	if u.ID == 0 {
 		r := rep.db.Exec(ctx, rep.db.Insert().
 			Into(rep.table()).
-			Record(u, "email"))
-		u.ID = int(r.LastID)
+			Record(u, "email").
+			Returning("id"))
 		return r.Err()
 	}
 
@@ -1,5 +1,7 @@
 module github.com/colinjfw/sqlkit
 
+go 1.16
+
 require (
 	github.com/davecgh/go-spew v1.1.0
 	github.com/go-sql-driver/mysql v1.3.0